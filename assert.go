@@ -0,0 +1,97 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+/* cond为false时以ERROR级别输出日志及调用栈，返回cond，SetAssertPanic开启时随后panic */
+/* 用法：if !zlog.Assertf(len(peers) > 0, "empty peer list for %s", shard) { return } */
+func (l *Logger) Assertf(cond bool, format string, v ...interface{}) bool {
+	if !cond {
+		l.assertf(format, v)
+	}
+	return cond
+}
+
+func (l *Logger) assertf(format string, v []interface{}) {
+	msg, fields := sprintf(format, v)
+	msg = "assertion failed: " + msg
+
+	c := l.caller(2, 0)
+	if l.wants(ERROR, c.pkg, c.file) {
+		l.output(c.entry(ERROR, msg+"\n"+callerStack(l.skip(2, 0)), fields...))
+	}
+
+	l.mu.RLock()
+	panics := l.assertPanic
+	l.mu.RUnlock()
+	if panics {
+		l.Sync()
+		panic("zlog: " + msg)
+	}
+}
+
+/* 设置Assertf失败时是否panic，用于开发及测试环境尽早暴露问题，NewDevelopment默认开启 */
+func (l *Logger) SetAssertPanic(on bool) {
+	l.mu.Lock()
+	l.assertPanic = on
+	l.mu.Unlock()
+}
+
+/* cond为false时以ERROR级别输出日志及调用栈，返回cond，SetAssertPanic开启时随后panic */
+func Assertf(cond bool, format string, v ...interface{}) bool {
+	if !cond {
+		std.assertf(format, v)
+	}
+	return cond
+}
+
+/* 设置默认日志记录器的Assertf失败时是否panic */
+func SetAssertPanic(on bool) {
+	std.SetAssertPanic(on)
+}
+
+/* 返回调用栈，skip为0时从callerStack的调用者开始，每帧为函数全名及缩进的源文件:行号两行 */
+func callerStack(skip int) string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+2, pcs[:])])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
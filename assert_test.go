@@ -0,0 +1,65 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAssertf(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(&TextFormatter{NoColor: true, Multiline: MultilineIndent}))
+
+	if !l.Assertf(true, "never") || buf.Len() != 0 {
+		t.Fatalf("passing assertion logged: %q", buf.String())
+	}
+	if l.Assertf(1 > 2, "expected %d peers", 3) {
+		t.Fatal("failing assertion returned true")
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "[ERROR][zlog: TestAssertf] assertion failed: expected 3 peers") {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if len(lines) < 3 || lines[1] != "\tgithub.com/atlaslee/zlog.TestAssertf" || !strings.Contains(lines[2], "assert_test.go:") {
+		t.Errorf("stack should start at the caller: %q", lines)
+	}
+}
+
+func TestAssertPanic(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithAssertPanic(true))
+
+	defer func() {
+		r := recover()
+		if r != "zlog: assertion failed: bad state" || !strings.Contains(buf.String(), "assertion failed: bad state") {
+			t.Errorf("recover() = %v, output %q", r, buf.String())
+		}
+	}()
+	l.Assertf(false, "bad state")
+	t.Error("Assertf did not panic")
+}
@@ -0,0 +1,202 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+/* 异步队列已满时的处理方式 */
+type Backpressure uint8
+
+const (
+	Block      Backpressure = iota /* 阻塞调用者直至队列有空位，不丢失日志 */
+	DropNewest                     /* 丢弃新的日志 */
+	DropOldest                     /* 丢弃队列中最早的日志 */
+)
+
+/* 异步输出的配置 */
+type AsyncConfig struct {
+	Size            int           /* 队列最多容纳的日志条数，默认1024 */
+	Policy          Backpressure  /* 队列已满时的处理方式 */
+	SummaryInterval time.Duration /* 有日志被丢弃时，调用OnDrop的最小间隔，默认10秒 */
+
+	/* 有日志被丢弃时由后台goroutine调用，n为上次调用以来丢弃的条数，total为累计丢弃的条数 */
+	/* 丢弃汇总不写入被包装的输出目标，以免破坏JSON、CBOR等格式的日志流，可在回调中经Logger记录 */
+	/* 如 OnDrop: func(n, total uint64) { zlog.Logw(zlog.WARNING, "log entries dropped", zlog.F("dropped", n), zlog.F("total", total)) } */
+	OnDrop func(n, total uint64)
+}
+
+var errAsyncClosed = errors.New("zlog: async writer closed")
+
+/* 由后台goroutine写入w的输出目标，使记录日志的调用不受慢速输出目标影响 */
+type AsyncWriter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	w        io.Writer
+	cfg      AsyncConfig
+	queue    [][]byte
+	writing  bool      /* 后台goroutine正在写入 */
+	closed   bool      /* 已关闭 */
+	dropped  uint64    /* 累计丢弃的条数 */
+	reported uint64    /* 已通过OnDrop报告的丢弃条数 */
+	summary  time.Time /* 最近一次调用OnDrop的时间 */
+	done     chan struct{}
+}
+
+func NewAsyncWriter(w io.Writer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.Size <= 0 {
+		cfg.Size = 1024
+	}
+	if cfg.SummaryInterval <= 0 {
+		cfg.SummaryInterval = 10 * time.Second
+	}
+
+	a := &AsyncWriter{w: w, cfg: cfg, done: make(chan struct{})}
+	a.cond = sync.NewCond(&a.mu)
+	go a.writeLoop()
+	return a
+}
+
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for len(a.queue) >= a.cfg.Size && !a.closed {
+		switch a.cfg.Policy {
+		case DropNewest:
+			a.dropped++
+			return len(p), nil
+		case DropOldest:
+			a.queue[0] = nil
+			a.queue = a.queue[1:]
+			a.dropped++
+		default:
+			a.cond.Wait()
+		}
+	}
+
+	if a.closed {
+		return 0, errAsyncClosed
+	}
+
+	a.queue = append(a.queue, append([]byte(nil), p...))
+	a.cond.Broadcast()
+	return len(p), nil
+}
+
+func (a *AsyncWriter) writeLoop() {
+	defer close(a.done)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for len(a.queue) == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if len(a.queue) == 0 {
+			return
+		}
+
+		p := a.queue[0]
+		a.queue[0] = nil
+		a.queue = a.queue[1:]
+		a.writing = true
+		n, total := a.dropReport()
+		a.cond.Broadcast()
+		a.mu.Unlock()
+
+		a.w.Write(p)
+		if n > 0 {
+			a.cfg.OnDrop(n, total)
+		}
+
+		a.mu.Lock()
+		a.writing = false
+		a.cond.Broadcast()
+	}
+}
+
+/* 设置了OnDrop、到达汇总间隔且有新丢弃的日志时返回新丢弃及累计丢弃的条数，否则n为0，调用方需持有a.mu */
+func (a *AsyncWriter) dropReport() (n, total uint64) {
+	if a.cfg.OnDrop == nil || a.dropped == a.reported || time.Since(a.summary) < a.cfg.SummaryInterval {
+		return 0, a.dropped
+	}
+
+	n = a.dropped - a.reported
+	a.reported, a.summary = a.dropped, time.Now()
+	return n, a.dropped
+}
+
+/* 返回队列中等待写入的条数 */
+func (a *AsyncWriter) QueueDepth() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.queue)
+}
+
+/* 返回累计丢弃的条数 */
+func (a *AsyncWriter) Dropped() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+/* 等待队列中的日志写完，并写出被包装输出目标缓冲的内容 */
+func (a *AsyncWriter) Flush() error {
+	a.mu.Lock()
+	for (len(a.queue) > 0 || a.writing) && !a.closed {
+		a.cond.Wait()
+	}
+	a.mu.Unlock()
+
+	return flushWriter(a.w)
+}
+
+/* 等待队列中的日志写完并落盘 */
+func (a *AsyncWriter) Sync() error {
+	err := a.Flush()
+	if serr := syncWriter(a.w); err == nil {
+		err = serr
+	}
+	return err
+}
+
+/* 写完队列中的日志后停止后台goroutine并关闭被包装的输出目标，标准输出及标准错误不会被关闭 */
+func (a *AsyncWriter) Close() error {
+	err := a.Flush()
+
+	a.mu.Lock()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+	<-a.done
+
+	if cerr := closeWriter(a.w); err == nil {
+		err = cerr
+	}
+	return err
+}
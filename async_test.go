@@ -0,0 +1,115 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* 在gate关闭前阻塞写入的输出目标 */
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	out  []string
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out = append(w.out, string(p))
+	return len(p), nil
+}
+
+func waitQueued(t *testing.T, a *AsyncWriter, depth int) {
+	deadline := time.Now().Add(time.Second)
+	for a.QueueDepth() != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth %d, want %d", a.QueueDepth(), depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncDropPolicies(t *testing.T) {
+	for policy, want := range map[Backpressure]string{
+		DropNewest: "0,1,2,",
+		DropOldest: "0,3,4,",
+	} {
+		gw := &gatedWriter{gate: make(chan struct{})}
+		var reported uint64
+		a := NewAsyncWriter(gw, AsyncConfig{Size: 2, Policy: policy, SummaryInterval: time.Nanosecond, OnDrop: func(n, total uint64) {
+			reported += n
+		}})
+
+		a.Write([]byte("0,"))
+		waitQueued(t, a, 0) /* 0正在写入，阻塞在gate */
+		for _, p := range []string{"1,", "2,", "3,", "4,"} {
+			a.Write([]byte(p))
+		}
+		if a.Dropped() != 2 || a.QueueDepth() != 2 {
+			t.Errorf("policy %d: dropped %d, want 2", policy, a.Dropped())
+		}
+
+		close(gw.gate)
+		a.Close()
+		if got := strings.Join(gw.out, ""); got != want || reported != 2 {
+			t.Errorf("policy %d: out = %q, reported = %d, want %q", policy, got, reported, want)
+		}
+	}
+}
+
+func TestAsyncBlock(t *testing.T) {
+	gw := &gatedWriter{gate: make(chan struct{})}
+	a := NewAsyncWriter(gw, AsyncConfig{Size: 1})
+
+	a.Write([]byte("a"))
+	waitQueued(t, a, 0)
+	a.Write([]byte("b"))
+
+	written := make(chan struct{})
+	go func() {
+		a.Write([]byte("c"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Write should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(gw.gate)
+	<-written
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(gw.out, ""); got != "abc" || a.Dropped() != 0 {
+		t.Errorf("out = %q, dropped = %d", got, a.Dropped())
+	}
+}
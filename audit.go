@@ -0,0 +1,55 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+)
+
+const AUDIT uint8 = FATAL + 5 /* 审计日志级别，仅用于显示，审计日志不受级别过滤 */
+
+var auditor = NewLogger() /* 审计日志专用的记录器 */
+
+func init() {
+	RegisterLevel(AUDIT, "AUDIT", "1;35")
+	auditor.private = true
+}
+
+/* 设置审计日志的输出目标，应与普通日志分开，如单独的文件 */
+func SetAuditOutput(w io.Writer) {
+	auditor.SetOutput(w)
+}
+
+/* 设置审计日志的格式 */
+func SetAuditFormatter(f Formatter) {
+	auditor.SetFormatter(f)
+}
+
+/* 记录一条安全相关的审计日志，不受任何级别设置影响，总会被输出 */
+/* 调用者的解析遵循默认日志记录器的SetCaller、AddCallerSkip及Helper，敏感信息按默认日志记录器的RedactKeys等规则脱敏 */
+/* 审计日志只写入审计输出目标，不进入Recent、Subscribe及调试接口 */
+func Audit(event string, fields ...Field) {
+	e := std.caller(1, 0).entry(AUDIT, event, fields...)
+	std.redact(e)
+	auditor.output(e)
+}
@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	var buf bytes.Buffer
+	SetAuditOutput(&buf)
+	defer SetAuditOutput(nil)
+
+	SetLevel(SILENCE)
+	defer SetLevel(VERBOSE)
+
+	Audit("user.login", F("user", "atlas"), F("ip", "10.0.0.1"))
+	if !strings.Contains(buf.String(), "AUDIT") || !strings.Contains(buf.String(), "user.login user=atlas ip=10.0.0.1") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func auditHelper(event string, fields ...Field) {
+	Helper()
+	Audit(event, fields...)
+}
+
+func TestAuditPrivate(t *testing.T) {
+	var buf bytes.Buffer
+	SetAuditOutput(&buf)
+	defer SetAuditOutput(nil)
+	SetRecentSize(10)
+	defer SetRecentSize(0)
+
+	std.mu.Lock()
+	saved := std.redactor
+	std.mu.Unlock()
+	defer func() {
+		std.mu.Lock()
+		std.redactor = saved
+		std.mu.Unlock()
+	}()
+	RedactKeys("session")
+
+	auditHelper("user.login", F("user", "atlas"), F("session", "s3cret"))
+	if s := buf.String(); !strings.Contains(s, "[zlog: TestAuditPrivate] user.login") || strings.Contains(s, "s3cret") {
+		t.Errorf("unexpected output: %q", s)
+	}
+	for _, e := range Recent() {
+		if e.Level == AUDIT {
+			t.Errorf("audit entry recorded: %+v", e)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+/* 以INFO级别输出带边框的启动信息，每行一条日志，不受日志级别限制，使每次启动都有统一、便于检索的开头 */
+/* kv为键值对，如"version", "1.4.2", "listen", ":8080"，之后附加构建信息及生效的日志级别 */
+func (l *Logger) Banner(appName string, kv ...interface{}) {
+	l.banner(appName, kv)
+}
+
+func (l *Logger) banner(appName string, kv []interface{}) {
+	var keys, values []string
+	add := func(key string, value interface{}) {
+		keys = append(keys, key)
+		values = append(values, fmt.Sprint(value))
+	}
+
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 < len(kv) {
+			add(fmt.Sprint(kv[i]), kv[i+1])
+		} else {
+			add(fmt.Sprint(kv[i]), "(missing)")
+		}
+	}
+	for _, f := range BuildFields() {
+		add(f.Key, f.Value)
+	}
+
+	add("level", LogLevelNames[uint8(atomic.LoadUint32(&l.level))])
+	for _, levels := range []map[string]uint8{l.loadTagLevels(), l.loadFileLevels()} {
+		names := make([]string, 0, len(levels))
+		for name := range levels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add("level "+name, LogLevelNames[levels[name]])
+		}
+	}
+
+	width := 0
+	for _, key := range keys {
+		if len(key) > width {
+			width = len(key)
+		}
+	}
+
+	lines := make([]string, len(keys))
+	border := len(appName) + 4
+	for i, key := range keys {
+		lines[i] = "| " + key + strings.Repeat(" ", width-len(key)) + "  " + values[i]
+		if len(lines[i]) > border {
+			border = len(lines[i])
+		}
+	}
+
+	c := l.caller(2, 0)
+	title := "== " + appName + " "
+	l.emit(c.entry(INFO, title+strings.Repeat("=", border-len(title))), true)
+	for _, line := range lines {
+		l.emit(c.entry(INFO, line), true)
+	}
+	l.emit(c.entry(INFO, strings.Repeat("=", border)), true)
+}
+
+/* 以默认日志记录器输出带边框的启动信息 */
+func Banner(appName string, kv ...interface{}) {
+	std.banner(appName, kv)
+}
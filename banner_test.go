@@ -0,0 +1,70 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBanner(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true, Caller: CallerTag})
+	l.SetLevel(WARNING)
+	l.SetTagLevel(DEBUG, "fpay/p2p")
+
+	l.Banner("gateway", "version", "1.4.2", "listen", ":8080", "odd")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var msgs []string
+	for _, line := range lines {
+		if !strings.Contains(line, "[INFO][zlog] ") {
+			t.Fatalf("unexpected line %q", line)
+		}
+		msgs = append(msgs, line[strings.Index(line, "] ")+2:])
+	}
+
+	if !strings.HasPrefix(msgs[0], "== gateway ==") || strings.Trim(msgs[len(msgs)-1], "=") != "" || len(msgs[0]) != len(msgs[len(msgs)-1]) {
+		t.Errorf("frame: %q", msgs)
+	}
+	for _, want := range []string{
+		"| version         1.4.2",
+		"| listen          :8080",
+		"| odd             (missing)",
+		"| level           WARNING",
+		"| level fpay/p2p  DEBUG",
+	} {
+		found := false
+		for _, msg := range msgs {
+			found = found || msg == want
+		}
+		if !found {
+			t.Errorf("missing %q in %q", want, msgs)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* 批量发送到远端服务的一条日志 */
+type batchItem struct {
+	time  time.Time
+	level uint8
+	tag   string
+	line  []byte /* 按远端格式编码的日志 */
+}
+
+var (
+	errBatcherClosed = errors.New("zlog: remote writer closed")
+	errQueueFull     = errors.New("zlog: remote writer queue full, entries dropped")
+)
+
+/* 批量发送日志，攒满size条或每隔interval由后台goroutine发送 */
+/* 写日志的goroutine不会因远端故障而阻塞：发送队列已满时写入暂存文件，没有暂存文件时丢弃 */
+type batcher struct {
+	mu      sync.Mutex
+	items   []batchItem
+	size    int
+	send    func([]batchItem) error
+	queue   chan []batchItem
+	qmu     sync.RWMutex /* 向queue发送时持有读锁，关闭queue时持有写锁 */
+	qclosed bool         /* queue是否已关闭，由qmu保护 */
+	pending sync.WaitGroup
+	err     error /* 最近一次发送最终失败的错误，在flush时返回 */
+	failed  error /* 最近一次发送失败的错误，发送成功后清除，用于add报告远端故障 */
+	closed  bool  /* 是否已调用close */
+	depth   int64 /* 尚未发送完成的条数，原子读写 */
+	spill   *SpillFile
+	stop    chan struct{}
+	once    sync.Once
+}
+
+/* spill不为nil时发送最终失败或队列已满的日志写入暂存文件 */
+func newBatcher(size int, interval time.Duration, spill *SpillFile, send func([]batchItem) error) *batcher {
+	if size <= 0 {
+		size = 100
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	b := &batcher{size: size, send: send, spill: spill, queue: make(chan []batchItem, 8), stop: make(chan struct{})}
+	go b.sendLoop()
+	go b.flushLoop(interval)
+	return b
+}
+
+func (b *batcher) sendLoop() {
+	for items := range b.queue {
+		err := b.send(items)
+		if err != nil && b.spill != nil && b.spill.spill(items) == nil {
+			err = fmt.Errorf("%v (%d entries spilled to %s)", err, len(items), b.spill.path)
+		}
+
+		b.mu.Lock()
+		if err != nil {
+			b.err = err
+		}
+		b.failed = err
+		b.mu.Unlock()
+
+		atomic.AddInt64(&b.depth, -int64(len(items)))
+		b.pending.Done()
+	}
+}
+
+func (b *batcher) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			items := b.take()
+			b.mu.Unlock()
+			b.enqueue(items, true)
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+/* 取出攒下的日志，调用方需持有b.mu */
+func (b *batcher) take() []batchItem {
+	items := b.items
+	b.items = nil
+	if len(items) > 0 {
+		b.pending.Add(1)
+	}
+	return items
+}
+
+/* 将take取出的日志放入发送队列，调用方不能持有b.mu */
+/* block为false时不等待：队列已满时写入暂存文件，暂存失败或没有暂存文件时丢弃并返回errQueueFull */
+func (b *batcher) enqueue(items []batchItem, block bool) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	b.qmu.RLock()
+	defer b.qmu.RUnlock()
+
+	if !b.qclosed {
+		if block {
+			b.queue <- items
+			return nil
+		}
+
+		select {
+		case b.queue <- items:
+			return nil
+		default:
+		}
+	}
+
+	atomic.AddInt64(&b.depth, -int64(len(items)))
+	b.pending.Done()
+	if b.qclosed {
+		return errBatcherClosed
+	}
+	if b.spill != nil && b.spill.spill(items) == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	b.err = errQueueFull
+	b.mu.Unlock()
+	return errQueueFull
+}
+
+/* 加入一条日志，close之后返回errBatcherClosed，队列已满而丢弃时返回errQueueFull */
+/* 远端最近一次发送失败时返回该错误，以便FallbackWriter等发现故障，此时日志仍已加入队列 */
+func (b *batcher) add(item batchItem) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errBatcherClosed
+	}
+
+	atomic.AddInt64(&b.depth, 1)
+	b.items = append(b.items, item)
+	var items []batchItem
+	if len(b.items) >= b.size {
+		items = b.take()
+	}
+	failed := b.failed
+	b.mu.Unlock()
+
+	if err := b.enqueue(items, false); err != nil {
+		return err
+	}
+	return failed
+}
+
+/* 尚未发送完成的条数 */
+func (b *batcher) queueDepth() int {
+	return int(atomic.LoadInt64(&b.depth))
+}
+
+/* 发送剩余的日志并等待发送完成，返回此前发送最终失败或因队列已满丢弃的错误 */
+func (b *batcher) flush() error {
+	b.mu.Lock()
+	items := b.take()
+	b.mu.Unlock()
+
+	b.enqueue(items, true)
+	b.pending.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+/* 重新发送暂存文件中的日志 */
+func (b *batcher) replay() error {
+	if b.spill == nil {
+		return nil
+	}
+	return b.spill.replay(b.send)
+}
+
+/* 发送剩余的日志并停止后台goroutine，之后add返回errBatcherClosed */
+func (b *batcher) close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	err := b.flush()
+	b.once.Do(func() {
+		close(b.stop)
+		b.qmu.Lock()
+		b.qclosed = true
+		close(b.queue)
+		b.qmu.Unlock()
+	})
+	return err
+}
+
+const defaultMaxBackoff = 30 * time.Second /* 远端输出目标重试等待时间的默认上限 */
+
+/* 与RetryWriter相同，等待带随机抖动的backoff后返回加倍且不超过max的下次等待时间 */
+func sleepBackoff(backoff, max time.Duration) time.Duration {
+	time.Sleep(jitter(backoff))
+	if backoff *= 2; backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+/* 发送HTTP请求并返回响应内容，网络错误、429及5xx时以backoff起始、maxBackoff为上限的指数退避重试retries次 */
+func postWithRetry(client *http.Client, url string, header http.Header, body []byte, retries int, backoff, maxBackoff time.Duration) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	for i := 0; ; i++ {
+		resp, err := post(client, url, header, body)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := err.(permanentError); ok || i >= retries {
+			return nil, err
+		}
+
+		backoff = sleepBackoff(backoff, maxBackoff)
+	}
+}
+
+/* 重试无法恢复的错误，如400、401 */
+type permanentError struct {
+	error
+}
+
+const maxResponseBody = 1 << 20 /* 读取的响应内容上限 */
+
+func post(client *http.Client, url string, header http.Header, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, permanentError{err}
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	msg, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	switch {
+	case resp.StatusCode < 300:
+		return msg, err
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, fmt.Errorf("zlog: %s: %s %.512s", url, resp.Status, bytes.TrimSpace(msg))
+	default:
+		return nil, permanentError{fmt.Errorf("zlog: %s: %s %.512s", url, resp.Status, bytes.TrimSpace(msg))}
+	}
+}
@@ -0,0 +1,109 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* 一个调用处的输出频率及静默状态 */
+type siteRate struct {
+	start      time.Time /* 当前计数周期的开始时间 */
+	n          int
+	mutedUntil time.Time /* 静默结束时间，零值表示未静默 */
+	suppressed int       /* 静默期间丢弃的条数 */
+}
+
+/* 调用处的可读名称，如dial.go:42，源文件未知时为标志.函数 */
+func siteName(e *Entry) string {
+	if e.File == "" {
+		return lastPath(e.Tag) + "." + e.Func
+	}
+	return filepath.Base(e.File) + ":" + strconv.Itoa(e.Line)
+}
+
+/* 返回熔断规则的中间环节：同一调用处在per时间内超过ceiling条日志时静默mute时间，防止错误死循环写满磁盘 */
+/* 静默及恢复时各输出一条WARNING提示，恢复时附带丢弃的条数，FATAL日志不受影响 */
+/* 如 zlog.Use(zlog.SiteBreaker(1000, time.Second, time.Minute)) */
+func SiteBreaker(ceiling int, per, mute time.Duration) Middleware {
+	var (
+		mu    sync.Mutex
+		sites = make(map[sampleKey]*siteRate)
+	)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			if e.Level >= FATAL {
+				next.Handle(e)
+				return
+			}
+
+			key := sampleKey{file: e.File, line: e.Line}
+			if e.File == "" {
+				key.msg = e.Message
+			}
+
+			mu.Lock()
+			s, ok := sites[key]
+			if !ok {
+				s = &siteRate{start: e.Time}
+				sites[key] = s
+			}
+
+			var notice string
+			switch {
+			case e.Time.Before(s.mutedUntil):
+				s.suppressed++
+				mu.Unlock()
+				return
+			case !s.mutedUntil.IsZero():
+				notice = "unmuted site " + siteName(e) + " after dropping " + strconv.Itoa(s.suppressed) + " entries"
+				*s = siteRate{start: e.Time}
+			case e.Time.Sub(s.start) >= per:
+				s.start, s.n = e.Time, 0
+			}
+
+			s.n++
+			if s.n > ceiling {
+				s.mutedUntil = e.Time.Add(mute)
+				s.suppressed = 1
+				mu.Unlock()
+				next.Handle(breakerNotice(e, "muted site "+siteName(e)+" for "+mute.String()))
+				return
+			}
+			mu.Unlock()
+
+			if notice != "" {
+				next.Handle(breakerNotice(e, notice))
+			}
+			next.Handle(e)
+		})
+	}
+}
+
+func breakerNotice(e *Entry, msg string) *Entry {
+	return &Entry{Level: WARNING, Time: time.Now(), Tag: e.Tag, Func: e.Func, File: e.File, Line: e.Line, Message: msg}
+}
@@ -0,0 +1,67 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSiteBreaker(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.Use(SiteBreaker(3, time.Minute, 50*time.Millisecond))
+
+	loop := func(n int) {
+		for i := 0; i < n; i++ {
+			l.Errorln("retry failed")
+		}
+	}
+
+	loop(10)
+	l.Infoln("other site")
+	out := buf.String()
+	if n := strings.Count(out, "retry failed"); n != 3 {
+		t.Errorf("expected 3 before muting, got %d", n)
+	}
+	if !strings.Contains(out, "] muted site breaker_test.go:") || !strings.Contains(out, " for 50ms\n") {
+		t.Errorf("missing mute notice: %q", out)
+	}
+	if !strings.Contains(out, "other site") {
+		t.Errorf("other site muted: %q", out)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	buf.Reset()
+	loop(1)
+	out = buf.String()
+	if !strings.Contains(out, "after dropping 7 entries") || !strings.Contains(out, "retry failed") {
+		t.Errorf("unmute: %q", out)
+	}
+}
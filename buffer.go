@@ -0,0 +1,132 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBufferSize    = 64 << 10               /* 默认缓冲大小 */
+	DefaultFlushInterval = 100 * time.Millisecond /* 默认定时写出间隔 */
+)
+
+/* 批量写出的输出目标，缓冲写满或定时写出，用于减少文件及网络输出的系统调用次数 */
+/* 进程退出前应调用Flush或Logger.Sync，否则可能丢失最后interval内的日志 */
+type BufferedWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	buf  []byte
+	size int
+	err  error         /* 最近一次写出失败的错误，在下次Write或Flush时返回 */
+	stop chan struct{} /* 关闭时通知定时写出的goroutine退出 */
+	once sync.Once
+}
+
+/* 包装w为批量写出的输出目标，size<=0时使用DefaultBufferSize，interval<=0时不定时写出 */
+func NewBufferedWriter(w io.Writer, size int, interval time.Duration) *BufferedWriter {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	b := &BufferedWriter{w: w, buf: make([]byte, 0, size), size: size, stop: make(chan struct{})}
+	if interval > 0 {
+		go b.flushLoop(interval)
+	}
+	return b
+}
+
+func (b *BufferedWriter) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.Flush()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+/* 调用方需持有b.mu */
+func (b *BufferedWriter) flush() error {
+	if len(b.buf) > 0 {
+		if _, err := b.w.Write(b.buf); err != nil && b.err == nil {
+			b.err = err
+		}
+		b.buf = b.buf[:0]
+	}
+
+	err := b.err
+	b.err = nil
+	return err
+}
+
+func (b *BufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.buf)+len(p) > b.size {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(p) >= b.size {
+		return b.w.Write(p)
+	}
+
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+/* 写出缓冲的内容，返回此前写出失败的错误 */
+func (b *BufferedWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flush()
+}
+
+/* 写出缓冲的内容并落盘 */
+func (b *BufferedWriter) Sync() error {
+	err := b.Flush()
+	if serr := syncWriter(b.w); err == nil {
+		err = serr
+	}
+	return err
+}
+
+/* 写出缓冲的内容，停止定时写出并关闭被包装的输出目标，标准输出及标准错误不会被关闭 */
+func (b *BufferedWriter) Close() error {
+	b.once.Do(func() { close(b.stop) })
+
+	err := b.Flush()
+	if cerr := closeWriter(b.w); err == nil {
+		err = cerr
+	}
+	return err
+}
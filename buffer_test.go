@@ -0,0 +1,116 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* 记录Write调用次数的输出目标 */
+type countingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	err    error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writes++
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) snapshot() (string, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String(), w.writes
+}
+
+func TestBufferedWriter(t *testing.T) {
+	var cw countingWriter
+	b := NewBufferedWriter(&cw, 32, 0)
+
+	b.Write([]byte("0123456789\n"))
+	b.Write([]byte("0123456789\n"))
+	if _, writes := cw.snapshot(); writes != 0 {
+		t.Fatalf("writes = %d before buffer full", writes)
+	}
+
+	b.Write([]byte("0123456789\n"))
+	if out, writes := cw.snapshot(); writes != 1 || out != strings.Repeat("0123456789\n", 2) {
+		t.Fatalf("writes = %d, out = %q after overflow", writes, out)
+	}
+
+	b.Write([]byte(strings.Repeat("x", 40)))
+	if _, writes := cw.snapshot(); writes != 3 {
+		t.Fatalf("writes = %d, large writes should bypass the buffer", writes)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBufferedWriterInterval(t *testing.T) {
+	var cw countingWriter
+	l := NewLogger()
+	l.SetOutput(NewBufferedWriter(&cw, 0, 10*time.Millisecond))
+	defer l.Close()
+
+	l.Infoln("tick")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if out, _ := cw.snapshot(); strings.Contains(out, "tick") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("entry not flushed by timer")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBufferedWriterError(t *testing.T) {
+	cw := countingWriter{err: errors.New("disk full")}
+	b := NewBufferedWriter(&cw, 0, 0)
+
+	b.Write([]byte("lost\n"))
+	if err := b.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Flush() = %v, want disk full", err)
+	}
+	if err := b.Flush(); err != nil {
+		t.Errorf("second Flush() = %v, want nil", err)
+	}
+}
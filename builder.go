@@ -0,0 +1,157 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+const defaultBackups = 5 /* Builder默认保留的轮转文件数 */
+
+/* 链式构造Logger，与New及选项等价，供偏好该风格的调用方使用 */
+/* 用法：zlog.Builder().Level(zlog.INFO).File("app.log").Rotate(100 * zlog.MB).Console(true).Build() */
+type LoggerBuilder struct {
+	opts    []Option
+	format  bool        /* 是否指定了日志格式 */
+	file    string      /* 日志文件路径，为空时不写文件 */
+	maxSize int64       /* 日志文件的轮转大小 */
+	backups int         /* 保留的轮转文件数 */
+	console bool        /* 是否同时输出到标准错误 */
+	outputs []io.Writer /* 其他输出目标 */
+}
+
+/* 开始链式构造Logger，未指定输出目标时与NewLogger相同 */
+func Builder() *LoggerBuilder {
+	return &LoggerBuilder{backups: defaultBackups}
+}
+
+/* 设置全局日志级别 */
+func (b *LoggerBuilder) Level(level uint8) *LoggerBuilder {
+	b.opts = append(b.opts, WithLevel(level))
+	return b
+}
+
+/* 设置标志的日志级别 */
+func (b *LoggerBuilder) TagLevel(level uint8, tags ...string) *LoggerBuilder {
+	b.opts = append(b.opts, WithTagLevel(level, tags...))
+	return b
+}
+
+/* 设置日志格式，未指定时控制台使用着色文本，文件使用不着色的文本 */
+func (b *LoggerBuilder) Format(f Formatter) *LoggerBuilder {
+	b.opts = append(b.opts, WithFormat(f))
+	b.format = true
+	return b
+}
+
+/* 写入日志文件，目录不存在时创建 */
+func (b *LoggerBuilder) File(path string) *LoggerBuilder {
+	b.file = path
+	return b
+}
+
+/* 日志文件超过maxSize时轮转，默认不轮转 */
+func (b *LoggerBuilder) Rotate(maxSize int64) *LoggerBuilder {
+	b.maxSize = maxSize
+	return b
+}
+
+/* 设置保留的轮转文件数，默认5 */
+func (b *LoggerBuilder) Backups(n int) *LoggerBuilder {
+	b.backups = n
+	return b
+}
+
+/* 是否同时输出到标准错误 */
+func (b *LoggerBuilder) Console(on bool) *LoggerBuilder {
+	b.console = on
+	return b
+}
+
+/* 添加其他输出目标 */
+func (b *LoggerBuilder) Output(w io.Writer) *LoggerBuilder {
+	b.outputs = append(b.outputs, w)
+	return b
+}
+
+/* 设置采样 */
+func (b *LoggerBuilder) Sampling(first, thereafter uint64, tick time.Duration) *LoggerBuilder {
+	b.opts = append(b.opts, WithSampling(first, thereafter, tick))
+	return b
+}
+
+/* 对指定键名的值脱敏 */
+func (b *LoggerBuilder) Redact(keys ...string) *LoggerBuilder {
+	b.opts = append(b.opts, WithRedactKeys(keys...))
+	return b
+}
+
+/* 添加中间件 */
+func (b *LoggerBuilder) Use(mws ...Middleware) *LoggerBuilder {
+	b.opts = append(b.opts, WithMiddleware(mws...))
+	return b
+}
+
+/* 构造Logger，日志文件无法打开时返回错误 */
+/* 指定了多个输出目标时每条日志写入所有目标 */
+func (b *LoggerBuilder) Build() (*Logger, error) {
+	var ws []io.Writer
+	if b.file != "" {
+		fw, err := NewFileWriter(b.file, b.maxSize, b.backups)
+		if err != nil {
+			return nil, err
+		}
+
+		if b.format {
+			ws = append(ws, fw)
+		} else {
+			ws = append(ws, WithFormatter(fw, &TextFormatter{NoColor: true}))
+		}
+	}
+	if b.console {
+		ws = append(ws, os.Stderr)
+	}
+	ws = append(ws, b.outputs...)
+
+	l := New(b.opts...)
+	if len(ws) == 1 {
+		l.SetOutput(ws[0])
+	} else if len(ws) > 1 {
+		l.SetOutput(io.Discard) /* 所有日志都会匹配以下路由 */
+		for _, w := range ws {
+			l.Route(VERBOSE, 255, w)
+		}
+	}
+	return l, nil
+}
+
+/* 与Build相同，出错时panic，用于初始化全局变量 */
+func (b *LoggerBuilder) MustBuild() *Logger {
+	l, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
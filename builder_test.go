@@ -0,0 +1,72 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var extra bytes.Buffer
+	l, err := Builder().Level(INFO).File(path).Rotate(100 * MB).Output(&extra).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("hidden")
+	l.Warning("disk full")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, "hidden") || !strings.Contains(s, "[WARNING][zlog: TestBuilder] disk full") {
+		t.Errorf("unexpected file content: %q", s)
+	}
+	if s := extra.String(); !strings.Contains(s, "disk full") || !strings.Contains(s, "\x1b[") {
+		t.Errorf("unexpected extra output: %q", s)
+	}
+
+	if _, err := Builder().File(filepath.Join(path, "sub.log")).Build(); err == nil {
+		t.Error("expected error for unusable file path")
+	}
+}
+
+func TestBuilderFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := Builder().Format(&JSONFormatter{}).Output(&buf).Redact("token").MustBuild()
+	l.Info("login", F("token", "abc"))
+	if s := buf.String(); !strings.Contains(s, `"token":"***"`) {
+		t.Errorf("unexpected output: %q", s)
+	}
+}
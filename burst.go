@@ -0,0 +1,77 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"sync"
+	"time"
+)
+
+/* 滑动窗口内ERROR及以上级别日志的计数 */
+type errorBurst struct {
+	mu      sync.Mutex
+	times   []time.Time /* 最近threshold+1条日志的时间，环形使用 */
+	next    int         /* times中下一个写入位置，即最早的一条 */
+	filled  bool
+	tripped bool /* 已触发回调，窗口内的条数回落到阈值以下后重新计时 */
+	window  time.Duration
+	fn      func()
+}
+
+/* 当前日志是否使窗口内的条数首次超过阈值 */
+func (b *errorBurst) observe(t time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.times[b.next] = t
+	if b.next++; b.next == len(b.times) {
+		b.next, b.filled = 0, true
+	}
+
+	burst := b.filled && t.Sub(b.times[b.next]) < b.window
+	fire := burst && !b.tripped
+	b.tripped = burst
+	return fire
+}
+
+/* 任意window时间内ERROR及以上级别的日志超过threshold条时调用fn，用于触发熔断或告警 */
+/* 触发后条数回落到阈值以下才会再次触发，fn在写日志的goroutine中同步调用，可以记录日志，threshold小于1时按1处理 */
+func (l *Logger) OnErrorBurst(threshold int, window time.Duration, fn func()) {
+	if threshold < 1 {
+		threshold = 1
+	}
+	b := &errorBurst{times: make([]time.Time, threshold+1), window: window, fn: fn}
+	l.Use(func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			next.Handle(e)
+			if e.Level >= ERROR && b.observe(e.Time) {
+				b.fn()
+			}
+		})
+	})
+}
+
+/* 默认日志记录器任意window时间内ERROR及以上级别的日志超过threshold条时调用fn */
+func OnErrorBurst(threshold int, window time.Duration, fn func()) {
+	std.OnErrorBurst(threshold, window, fn)
+}
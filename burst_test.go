@@ -0,0 +1,88 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOnErrorBurst(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+
+	fired := 0
+	l.OnErrorBurst(3, time.Minute, func() {
+		fired++
+		l.Warningln("error burst")
+	})
+
+	for i := 0; i < 3; i++ {
+		l.Errorln("failed")
+	}
+	if fired != 0 {
+		t.Fatalf("fired at threshold")
+	}
+
+	l.Warningln("not counted")
+	l.Errorln("failed")
+	l.Errorln("failed")
+	if fired != 1 {
+		t.Fatalf("fired %d times", fired)
+	}
+
+	b := &errorBurst{times: make([]time.Time, 3), window: time.Second}
+	now := time.Now()
+	for i, c := range []struct {
+		at   time.Duration
+		fire bool
+	}{
+		{0, false},
+		{100 * time.Millisecond, false},
+		{200 * time.Millisecond, true},
+		{300 * time.Millisecond, false},
+		{5 * time.Second, false},
+		{5*time.Second + 100*time.Millisecond, false},
+		{5*time.Second + 200*time.Millisecond, true},
+	} {
+		if got := b.observe(now.Add(c.at)); got != c.fire {
+			t.Errorf("%d: fire=%v", i, got)
+		}
+	}
+}
+
+func TestOnErrorBurstThreshold(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+
+	fired := 0
+	l.OnErrorBurst(-1, time.Minute, func() { fired++ })
+	l.Errorln("failed")
+	l.Errorln("failed")
+	if fired != 1 {
+		t.Errorf("fired %d times", fired)
+	}
+}
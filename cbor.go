@@ -0,0 +1,160 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+/* CBOR(RFC 8949)主类型 */
+const (
+	cborUint   byte = 0 << 5
+	cborNegint byte = 1 << 5
+	cborBytes  byte = 2 << 5
+	cborText   byte = 3 << 5
+	cborMap    byte = 5 << 5
+	cborSimple byte = 7 << 5
+)
+
+/* CBOR格式，每条日志编码为一个定长map，键及值与MsgpackFormatter相同，记录之间没有分隔符 */
+type CBORFormatter struct{}
+
+func (f *CBORFormatter) binary() {}
+
+func (f *CBORFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	n := 5
+	if e.File != "" {
+		n += 2
+	}
+	if len(e.Fields) > 0 {
+		n++
+	}
+
+	cborHead(buf, cborMap, uint64(n))
+	cborString(buf, "t")
+	cborInt(buf, e.Time.UnixNano())
+	cborString(buf, "l")
+	cborHead(buf, cborUint, uint64(e.Level))
+	cborString(buf, "g")
+	cborString(buf, e.Tag)
+	cborString(buf, "c")
+	cborString(buf, e.Func)
+	if e.File != "" {
+		cborString(buf, "s")
+		cborString(buf, e.File)
+		cborString(buf, "n")
+		cborInt(buf, int64(e.Line))
+	}
+	cborString(buf, "m")
+	cborString(buf, e.Message)
+
+	if len(e.Fields) > 0 {
+		cborString(buf, "f")
+		cborHead(buf, cborMap, uint64(len(e.Fields)))
+		for _, field := range e.Fields {
+			cborString(buf, field.Key)
+			cborValue(buf, field.Value)
+		}
+	}
+}
+
+func cborValue(buf *bytes.Buffer, v interface{}) {
+	switch val := jsonValue(v).(type) {
+	case nil:
+		buf.WriteByte(cborSimple | 22)
+	case bool:
+		if val {
+			buf.WriteByte(cborSimple | 21)
+		} else {
+			buf.WriteByte(cborSimple | 20)
+		}
+	case int:
+		cborInt(buf, int64(val))
+	case int8:
+		cborInt(buf, int64(val))
+	case int16:
+		cborInt(buf, int64(val))
+	case int32:
+		cborInt(buf, int64(val))
+	case int64:
+		cborInt(buf, val)
+	case uint:
+		cborHead(buf, cborUint, uint64(val))
+	case uint8:
+		cborHead(buf, cborUint, uint64(val))
+	case uint16:
+		cborHead(buf, cborUint, uint64(val))
+	case uint32:
+		cborHead(buf, cborUint, uint64(val))
+	case uint64:
+		cborHead(buf, cborUint, val)
+	case float32:
+		buf.WriteByte(cborSimple | 26)
+		mpBig(buf, 4, uint64(math.Float32bits(val)))
+	case float64:
+		buf.WriteByte(cborSimple | 27)
+		mpBig(buf, 8, math.Float64bits(val))
+	case string:
+		cborString(buf, val)
+	case []byte:
+		cborHead(buf, cborBytes, uint64(len(val)))
+		buf.Write(val)
+	default:
+		cborString(buf, fmt.Sprint(val))
+	}
+}
+
+/* 写入主类型及参数，参数按大小使用最短编码 */
+func cborHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		mpBig(buf, 1, n)
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		mpBig(buf, 2, n)
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		mpBig(buf, 4, n)
+	default:
+		buf.WriteByte(major | 27)
+		mpBig(buf, 8, n)
+	}
+}
+
+func cborInt(buf *bytes.Buffer, v int64) {
+	if v >= 0 {
+		cborHead(buf, cborUint, uint64(v))
+	} else {
+		cborHead(buf, cborNegint, uint64(-1-v))
+	}
+}
+
+func cborString(buf *bytes.Buffer, s string) {
+	cborHead(buf, cborText, uint64(len(s)))
+	buf.WriteString(s)
+}
@@ -0,0 +1,47 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestCBORFormatter(t *testing.T) {
+	e := newEntry(INFO, "p2p", "Dial", "hi", F("n", -500), F("ok", true), F("f", 0.5))
+	e.Time = time.Unix(0, 1)
+
+	var buf bytes.Buffer
+	(&CBORFormatter{}).Format(&buf, e)
+
+	/* {"t": 1, "l": 30, "g": "p2p", "c": "Dial", "m": "hi", "f": {"n": -500, "ok": true, "f": 0.5}} */
+	want := "a6" + "617401" + "616c181e" + "616763703270" + "6163644469616c" + "616d626869" +
+		"6166a3" + "616e3901f3" + "626f6bf5" + "6166fb3fe0000000000000"
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
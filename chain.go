@@ -0,0 +1,162 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+/* 防篡改的哈希链输出目标，在每行日志末尾追加 seq=序号 prev=上一行的SHA-256 */
+/* 设置key时在第一行及每every行追加 sig=对该行内容及序号的ed25519签名，作为无法伪造的检查点 */
+/* 适用于按行输出的文本格式，如 zlog.SetAuditOutput(zlog.NewChainWriter(f, key, 100))，用VerifyChain校验 */
+type ChainWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	key   ed25519.PrivateKey
+	every uint64
+	seq   uint64
+	prev  [sha256.Size]byte
+}
+
+/* 包装w为哈希链输出目标，key为nil时不输出签名，every为0时只签名第一行，序号自1开始 */
+func NewChainWriter(w io.Writer, key ed25519.PrivateKey, every uint64) *ChainWriter {
+	return &ChainWriter{w: w, key: key, every: every}
+}
+
+/* 检查点签名的内容：该行不含sig部分的SHA-256(其中已包含上一行的哈希)及大端序的序号 */
+func chainSigned(line []byte, seq uint64) []byte {
+	sum := sha256.Sum256(line)
+	return binary.BigEndian.AppendUint64(sum[:], seq)
+}
+
+/* 序号为seq的行是否为检查点，第一行总是检查点，以免从seq=1伪造新的链 */
+func chainCheckpoint(seq, every uint64) bool {
+	return seq == 1 || every > 0 && seq%every == 0
+}
+
+/* p应为一行或多行完整的日志 */
+func (c *ChainWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) == 0 {
+			continue
+		}
+
+		c.seq++
+		start := out.Len()
+		out.Write(line)
+		fmt.Fprintf(&out, " seq=%d prev=%s", c.seq, hex.EncodeToString(c.prev[:]))
+		if c.key != nil && chainCheckpoint(c.seq, c.every) {
+			sig := ed25519.Sign(c.key, chainSigned(out.Bytes()[start:], c.seq))
+			out.WriteString(" sig=")
+			out.WriteString(base64.RawStdEncoding.EncodeToString(sig))
+		}
+		c.prev = sha256.Sum256(out.Bytes()[start:])
+		out.WriteByte('\n')
+	}
+
+	if _, err := c.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/* 写出被包装输出目标缓冲的内容 */
+func (c *ChainWriter) Flush() error {
+	return flushWriter(c.w)
+}
+
+/* 落盘被包装的输出目标 */
+func (c *ChainWriter) Sync() error {
+	return syncWriter(c.w)
+}
+
+/* 关闭被包装的输出目标，标准输出及标准错误不会被关闭 */
+func (c *ChainWriter) Close() error {
+	return closeWriter(c.w)
+}
+
+var chainSuffix = regexp.MustCompile(` seq=(\d+) prev=([0-9a-f]{64})(?: sig=([A-Za-z0-9+/]+))?$`)
+
+/* 校验ChainWriter输出的日志，every应与NewChainWriter的相同 */
+/* pub不为nil时要求每个检查点(包括每次从seq=1重新开始的行)都有有效的签名 */
+/* 进程重启后链从seq=1重新开始，最后一个检查点之后被删除的日志无法发现 */
+func VerifyChain(r io.Reader, pub ed25519.PublicKey, every uint64) error {
+	var prev [sha256.Size]byte
+	var seq uint64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxPooledBuffer*16)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		m := chainSuffix.FindSubmatchIndex(line)
+		if m == nil {
+			return fmt.Errorf("zlog: line %d: missing chain fields", n)
+		}
+
+		lineSeq, err := strconv.ParseUint(string(line[m[2]:m[3]]), 10, 64)
+		if err != nil {
+			return fmt.Errorf("zlog: line %d: %v", n, err)
+		}
+
+		if lineSeq == 1 {
+			prev = [sha256.Size]byte{}
+		} else if lineSeq != seq+1 {
+			return fmt.Errorf("zlog: line %d: seq %d follows %d", n, lineSeq, seq)
+		}
+
+		if string(line[m[4]:m[5]]) != hex.EncodeToString(prev[:]) {
+			return fmt.Errorf("zlog: line %d: hash chain broken", n)
+		}
+
+		if pub != nil && (m[6] >= 0 || chainCheckpoint(lineSeq, every)) {
+			if m[6] < 0 {
+				return fmt.Errorf("zlog: line %d: missing checkpoint signature", n)
+			}
+
+			sig, err := base64.RawStdEncoding.DecodeString(string(line[m[6]:m[7]]))
+			unsigned := line[:m[6]-len(" sig=")]
+			if err != nil || !ed25519.Verify(pub, chainSigned(unsigned, lineSeq), sig) {
+				return fmt.Errorf("zlog: line %d: invalid checkpoint signature", n)
+			}
+		}
+
+		seq = lineSeq
+		prev = sha256.Sum256(line)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+func TestChainWriter(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(NewChainWriter(&buf, key, 2))
+	for _, msg := range []string{"login", "transfer", "logout"} {
+		l.Infoln(msg)
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if !strings.Contains(lines[0], " seq=1 prev=0000") || !strings.Contains(lines[0], " sig=") || !strings.Contains(lines[1], " sig=") || strings.Contains(lines[2], " sig=") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+	if err := VerifyChain(strings.NewReader(buf.String()), pub, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tampered := range map[string]string{
+		"modified": strings.Replace(buf.String(), "transfer", "transfeR", 1),
+		"deleted":  lines[0] + lines[2],
+		"forged":   strings.Replace(buf.String(), lines[1], strings.Replace(lines[1], "transfer", "x", 1), 1),
+	} {
+		if err := VerifyChain(strings.NewReader(tampered), pub, 2); err == nil {
+			t.Errorf("%s log passed verification", name)
+		}
+	}
+
+	/* 去掉签名并修改内容后重建不带签名的链 */
+	var forged bytes.Buffer
+	fw := NewChainWriter(&forged, nil, 0)
+	for _, line := range lines {
+		if line = chainSuffix.ReplaceAllString(strings.TrimSuffix(line, "\n"), ""); line != "" {
+			fw.Write([]byte(strings.Replace(line, "transfer", "transfer 999999", 1) + "\n"))
+		}
+	}
+	if err := VerifyChain(&forged, pub, 2); err == nil {
+		t.Error("unsigned rebuilt chain passed verification")
+	}
+
+	/* 签名后修改该行内容，即使重新计算后续哈希也无法通过 */
+	var resigned bytes.Buffer
+	rw := NewChainWriter(&resigned, key, 2)
+	rw.Write([]byte("login\ntransfer 100\n"))
+	if err := VerifyChain(strings.NewReader(strings.Replace(resigned.String(), "transfer 100", "transfer 999", 1)), pub, 2); err == nil {
+		t.Error("signed line content not covered by signature")
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if err := VerifyChain(strings.NewReader(buf.String()), otherPub, 2); err == nil {
+		t.Error("signature verified with wrong key")
+	}
+}
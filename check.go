@@ -0,0 +1,107 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+/* err不为nil时以ERROR级别输出日志及错误链，返回err是否不为nil */
+/* 用法：if zlog.ErrorIf(err, "saving user %d", id) { return } */
+func (l *Logger) ErrorIf(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+
+	l.logef(0, ERROR, err, format, v)
+	return true
+}
+
+/* err不为nil时以WARNING级别输出日志及错误链，返回err是否不为nil */
+func (l *Logger) WarningIf(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+
+	l.logef(0, WARNING, err, format, v)
+	return true
+}
+
+/* err不为nil时以ERROR级别输出日志及错误链，返回err是否不为nil */
+func ErrorIf(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+
+	std.logef(0, ERROR, err, format, v)
+	return true
+}
+
+/* err不为nil时以WARNING级别输出日志及错误链，返回err是否不为nil */
+func WarningIf(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return false
+	}
+
+	std.logef(0, WARNING, err, format, v)
+	return true
+}
+
+/* err不为nil时以ERROR级别输出日志及错误链，返回err是否为nil，即检查是否通过 */
+/* 用法：if !zlog.Check(err, "loading config") { return } */
+func (l *Logger) Check(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return true
+	}
+
+	l.logef(0, ERROR, err, format, v)
+	return false
+}
+
+/* err不为nil时以FATAL级别输出日志及错误链，执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+/* 用法：zlog.MustOK(db.Ping(), "connecting %s", dsn) */
+func (l *Logger) MustOK(err error, format string, v ...interface{}) {
+	if err == nil {
+		return
+	}
+
+	l.logef(0, FATAL, err, format, v)
+	l.Sync()
+	Exit(l.fatalCode())
+}
+
+/* err不为nil时以ERROR级别输出日志及错误链，返回err是否为nil，即检查是否通过 */
+func Check(err error, format string, v ...interface{}) bool {
+	if err == nil {
+		return true
+	}
+
+	std.logef(0, ERROR, err, format, v)
+	return false
+}
+
+/* err不为nil时以FATAL级别输出日志及错误链，执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func MustOK(err error, format string, v ...interface{}) {
+	if err == nil {
+		return
+	}
+
+	std.logef(0, FATAL, err, format, v)
+	Exit(std.fatalCode())
+}
@@ -0,0 +1,86 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestErrorIf(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	if l.ErrorIf(nil, "saving user %d", 1) || buf.Len() != 0 {
+		t.Fatalf("logged nil error: %q", buf.String())
+	}
+
+	if !l.ErrorIf(errors.New("disk full"), "saving user %d", 2) {
+		t.Fatal("ErrorIf returned false for non-nil error")
+	}
+	if !l.WarningIf(errors.New("retrying"), "syncing") {
+		t.Fatal("WarningIf returned false for non-nil error")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `ERROR`) || !strings.Contains(out, `saving user 2 error="disk full"`) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if !strings.Contains(out, `WARNING`) || !strings.Contains(out, `syncing error=retrying`) {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(&TextFormatter{NoColor: true}))
+	if !l.Check(nil, "loading config") {
+		t.Error("Check(nil) should pass")
+	}
+	l.MustOK(nil, "connecting")
+	if buf.Len() != 0 || code != 0 {
+		t.Fatalf("nil error logged or exited: code=%d %q", code, buf.String())
+	}
+
+	if l.Check(errors.New("no such file"), "loading %s", "app.yaml") {
+		t.Error("Check should fail for non-nil error")
+	}
+	l.MustOK(errors.New("refused"), "connecting")
+	out := buf.String()
+	if !strings.Contains(out, `[ERROR][zlog: TestCheck] loading app.yaml error="no such file"`) || !strings.Contains(out, `[FATAL][zlog: TestCheck] connecting error=refused`) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
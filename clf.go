@@ -0,0 +1,148 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+)
+
+/* Apache通用日志格式(CLF)，Combined为真时为组合日志格式，附加Referer及User-Agent */
+/* 用于AccessLog的日志，供处理Apache日志的工具读取，通常输出到单独的文件： */
+/* zlog.SetTagOutput(zlog.WithFormatter(file, &zlog.CommonLogFormatter{Combined: true}), zlog.AccessTag) */
+type CommonLogFormatter struct {
+	Combined bool
+}
+
+func (f *CommonLogFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	var remote, proto, query, referer, agent, user string
+	var status, size int
+	for _, field := range e.Fields {
+		switch v := field.Value.(type) {
+		case string:
+			switch field.Key {
+			case "remote":
+				remote = v
+			case "proto":
+				proto = v
+			case "query":
+				query = v
+			case "referer":
+				referer = v
+			case "user_agent":
+				agent = v
+			case "user":
+				user = v
+			}
+		case int:
+			switch field.Key {
+			case "status":
+				status = v
+			case "bytes":
+				size = v
+			}
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	buf.WriteString(clfToken(remote))
+	buf.WriteString(" - ")
+	buf.WriteString(clfToken(user))
+
+	var scratch [32]byte
+	buf.WriteString(" [")
+	buf.Write(e.Time.AppendFormat(scratch[:0], "02/Jan/2006:15:04:05 -0700"))
+	buf.WriteString(`] "`)
+	request := e.Message
+	if query != "" {
+		request += "?" + query
+	}
+	if proto != "" {
+		request += " " + proto
+	}
+	buf.WriteString(clfEscape(request))
+	buf.WriteString(`" `)
+
+	if status > 0 {
+		buf.Write(strconv.AppendInt(scratch[:0], int64(status), 10))
+	} else {
+		buf.WriteByte('-')
+	}
+	buf.WriteByte(' ')
+	if size > 0 {
+		buf.Write(strconv.AppendInt(scratch[:0], int64(size), 10))
+	} else {
+		buf.WriteByte('-')
+	}
+
+	if f.Combined {
+		buf.WriteString(` "`)
+		buf.WriteString(clfEscape(clfValue(referer)))
+		buf.WriteString(`" "`)
+		buf.WriteString(clfEscape(clfValue(agent)))
+		buf.WriteByte('"')
+	}
+}
+
+/* 空值以-表示 */
+func clfValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+/* 转义不在引号内的字段，如客户端提供的Basic认证用户名 */
+/* 与Apache的%u相同，引号及反斜杠前加\，空白、控制字符及方括号转义为\xhh，避免伪造字段或日志行 */
+func clfToken(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c <= ' ' || c == 0x7f || c == '[' || c == ']':
+			b.WriteString(`\x`)
+			b.WriteByte("0123456789abcdef"[c>>4])
+			b.WriteByte("0123456789abcdef"[c&0xf])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+var clfReplacer = strings.NewReplacer(`"`, `\"`, `\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+/* 转义引号内的内容，避免伪造日志行 */
+func clfEscape(s string) string {
+	return clfReplacer.Replace(s)
+}
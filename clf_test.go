@@ -0,0 +1,92 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommonLogFormatter(t *testing.T) {
+	e := newEntry(INFO, AccessTag, "AccessLog", "GET /apache_pb.gif",
+		F("status", 200), F("latency", time.Millisecond), F("bytes", 2326), F("remote", "127.0.0.1:51234"),
+		F("proto", "HTTP/1.0"), F("query", "a=1"), F("referer", "http://www.example.com/start.html"),
+		F("user_agent", `Mozilla/4.08 "x"`), F("user", "frank"))
+	e.Time = time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+
+	var buf bytes.Buffer
+	(&CommonLogFormatter{}).Format(&buf, e)
+	if want := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 2326`; buf.String() != want {
+		t.Errorf("common:\ngot  %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	(&CommonLogFormatter{Combined: true}).Format(&buf, e)
+	if want := `"http://www.example.com/start.html" "Mozilla/4.08 \"x\""`; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("combined: %q", buf.String())
+	}
+}
+
+func TestCommonLogAccess(t *testing.T) {
+	var buf, access bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetTagOutput(WithFormatter(&access, &CommonLogFormatter{Combined: true}), AccessTag)
+
+	h := l.AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 0)
+	r := httptest.NewRequest("GET", "/ok", nil)
+	r.Header.Set("User-Agent", "curl/8.0")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	out := access.String()
+	if !strings.HasPrefix(out, "192.0.2.1 - - [") || !strings.HasSuffix(out, `] "GET /ok HTTP/1.1" 200 - "-" "curl/8.0"`+"\n") {
+		t.Errorf("access: %q", out)
+	}
+	if !strings.Contains(buf.String(), "GET /ok status=200") {
+		t.Errorf("default: %q", buf.String())
+	}
+}
+
+func TestCommonLogUserInjection(t *testing.T) {
+	var access bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+	l.SetTagOutput(WithFormatter(&access, &CommonLogFormatter{}), AccessTag)
+
+	h := l.AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 0)
+	r := httptest.NewRequest("GET", "/ok", nil)
+	r.SetBasicAuth("evil\n10.0.0.1 - admin [x]", "pw")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	out := access.String()
+	if strings.Count(out, "\n") != 1 || !strings.HasPrefix(out, `192.0.2.1 - evil\x0a10.0.0.1\x20-\x20admin\x20\x5bx\x5d [`) {
+		t.Errorf("access: %q", out)
+	}
+}
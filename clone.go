@@ -0,0 +1,88 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"regexp"
+	"sync/atomic"
+)
+
+/* 返回配置相同的独立Logger，之后对任一方的修改互不影响 */
+/* 输出目标、格式、自定义过滤规则、中间件及处理流程的最后一环为共用，执行次数、采样及丢弃的计数重新开始 */
+/* 命名Logger的副本同样写入上级的输出目标，但不在Loggers中，也不再随上级更新级别 */
+func (l *Logger) Clone() *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c := &Logger{
+		level:         atomic.LoadUint32(&l.level),
+		minLevel:      atomic.LoadUint32(&l.minLevel),
+		callerSkip:    atomic.LoadInt32(&l.callerSkip),
+		noCaller:      atomic.LoadUint32(&l.noCaller),
+		exitCode:      atomic.LoadInt32(&l.exitCode),
+		out:           l.out,
+		errOut:        l.errOut,
+		routes:        append([]route(nil), l.routes...),
+		tagRoutes:     append([]tagRoute(nil), l.tagRoutes...),
+		formatter:     l.formatter,
+		tagFormatters: append([]tagFormatter(nil), l.tagFormatters...),
+		filter: messageFilter{
+			drop: append([]*regexp.Regexp(nil), l.filter.drop...),
+			keep: append([]*regexp.Regexp(nil), l.filter.keep...),
+		},
+		filters: append([]Filter(nil), l.filters...),
+		redactor: redactor{
+			keys:     append([]string(nil), l.redactor.keys...),
+			keyValue: l.redactor.keyValue,
+			patterns: append([]*regexp.Regexp(nil), l.redactor.patterns...),
+		},
+		handler:     l.handler,
+		middlewares: append([]Middleware(nil), l.middlewares...),
+		name:        l.name,
+		parent:      l.parent,
+		propagate:   l.propagate,
+		assertPanic: l.assertPanic,
+	}
+
+	/* 级别表修改时整体替换，可以直接共用 */
+	c.tagLevels.Store(l.loadTagLevels())
+	c.fileLevels.Store(l.loadFileLevels())
+	if s := l.sampler; s != nil {
+		c.sampler = &sampler{first: s.first, thereafter: s.thereafter, tick: s.tick}
+	}
+	if s := l.shedder; s != nil {
+		c.shedder = &shedder{cfg: s.cfg}
+	}
+	c.buildPipeline()
+	return c
+}
+
+/* 返回应用了选项的副本，用于子系统使用不同的级别或输出目标而不影响共用的Logger */
+/* 用法：dbLog := zlog.Default().WithOptions(zlog.WithLevel(zlog.DEBUG), zlog.WithOutput(f)) */
+func (l *Logger) WithOptions(opts ...Option) *Logger {
+	c := l.Clone()
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
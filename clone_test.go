@@ -0,0 +1,76 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	var base, sub bytes.Buffer
+	l := New(WithLevel(INFO), WithTagLevel(DEBUG, "db"), WithOutput(&base), WithRedactKeys("token"))
+	if err := l.DropMatching("^noise"); err != nil {
+		t.Fatal(err)
+	}
+
+	c := l.WithOptions(WithLevel(DEBUG), WithOutput(&sub))
+	c.RedactKeys("secret")
+	c.SetTagLevel(ERROR, "db")
+
+	l.Debug("base debug")
+	l.Info("base info", F("secret", "s1"))
+	c.Debug("clone debug", F("token", "t1"), F("secret", "s2"))
+	c.Info("noise from clone")
+
+	if s := base.String(); strings.Contains(s, "debug") || !strings.Contains(s, "base info secret=s1") {
+		t.Errorf("clone changed the original: %q", s)
+	}
+	if s := sub.String(); !strings.Contains(s, "clone debug token=*** secret=***") || strings.Contains(s, "noise") || strings.Contains(s, "base") {
+		t.Errorf("unexpected clone output: %q", s)
+	}
+	if !l.Enabled(DEBUG, "db") || c.Enabled(WARNING, "db") {
+		t.Error("tag levels should be independent")
+	}
+}
+
+func TestCloneMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	var seen int
+	l := New(WithOutput(&buf), WithMiddleware(func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			seen++
+			next.Handle(e)
+		})
+	}))
+
+	var sub bytes.Buffer
+	c := l.WithOptions(WithOutput(&sub))
+	c.Info("via clone")
+	if seen != 1 || buf.Len() != 0 || !strings.Contains(sub.String(), "via clone") {
+		t.Errorf("seen=%d base=%q clone=%q", seen, buf.String(), sub.String())
+	}
+}
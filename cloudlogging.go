@@ -0,0 +1,84 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+/* Google Cloud Logging识别的JSON格式，GKE等环境采集标准输出时据此区分级别及源码位置 */
+/* 结构化字段作为顶层键输出，与保留键重名时被覆盖 */
+type CloudLoggingFormatter struct{}
+
+/* Cloud Logging的源码位置 */
+type cloudSourceLocation struct {
+	File     string `json:"file,omitempty"`
+	Line     string `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+/* 级别对应的Cloud Logging severity，自定义级别按所在区间对应 */
+func cloudSeverity(level uint8) string {
+	switch {
+	case level < INFO:
+		return "DEBUG"
+	case level == INFO:
+		return "INFO"
+	case level < WARNING:
+		return "NOTICE"
+	case level < ERROR:
+		return "WARNING"
+	case level < FATAL:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
+
+func (f *CloudLoggingFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	m := fieldsMap(e.Fields)
+	if m == nil {
+		m = make(map[string]interface{}, 4)
+	}
+
+	m["severity"] = cloudSeverity(e.Level)
+	m["time"] = e.Time
+	m["message"] = e.Message
+	loc := cloudSourceLocation{File: e.File, Function: lastPath(e.Tag) + "." + e.Func}
+	if e.Line > 0 {
+		loc.Line = strconv.Itoa(e.Line)
+	}
+	m["logging.googleapis.com/sourceLocation"] = loc
+	if e.Tag != "" {
+		m["tag"] = e.Tag
+	}
+
+	if err := json.NewEncoder(buf).Encode(m); err != nil {
+		buf.WriteString(`{"severity":"ERROR","message":`)
+		b, _ := json.Marshal("zlog: " + err.Error())
+		buf.Write(b)
+		buf.WriteString("}\n")
+	}
+}
@@ -0,0 +1,74 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCloudLoggingFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&CloudLoggingFormatter{})
+	l.Logw(WARNING, "disk almost full", F("free", 12))
+	l.Logw(FATAL+1, "bad", F("message", "shadowed"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %q", buf.String())
+	}
+
+	var got struct {
+		Severity string
+		Message  string
+		Free     int
+		Tag      string
+		Location struct {
+			File     string
+			Line     string
+			Function string
+		} `json:"logging.googleapis.com/sourceLocation"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Severity != "WARNING" || got.Message != "disk almost full" || got.Free != 12 || got.Tag == "" {
+		t.Errorf("got %+v", got)
+	}
+	if !strings.HasSuffix(got.Location.File, "cloudlogging_test.go") || got.Location.Line == "" || got.Location.Function != "zlog.TestCloudLoggingFormatter" {
+		t.Errorf("sourceLocation = %+v", got.Location)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Severity != "CRITICAL" || got.Message != "bad" {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,106 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+/* 适合本地开发的控制台格式：暗色时间、定宽的级别及标志、着色的字段名，各列对齐 */
+/* 如 15:04:05.000 INFO    p2p          Dial: connected peer=10.0.0.2 */
+type ConsoleFormatter struct {
+	TagWidth int  /* 标志列的宽度，默认12，超长时截断开头 */
+	NoColor  bool /* 不输出控制台颜色 */
+	ShowFunc bool /* 在内容前输出调用者函数名 */
+}
+
+const (
+	consoleDim   = "2"
+	consoleKey   = "36"
+	defaultWidth = 12
+)
+
+func (f *ConsoleFormatter) color(buf *bytes.Buffer, color, s string) {
+	if f.NoColor || color == "" {
+		buf.WriteString(s)
+		return
+	}
+
+	buf.WriteString("\x1b[")
+	buf.WriteString(color)
+	buf.WriteByte('m')
+	buf.WriteString(s)
+	buf.WriteString("\x1b[0m")
+}
+
+/* 将s写为width宽，不足时补空格，超出时保留末尾 */
+func padColumn(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		return "…" + string(runes[n-width+1:])
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+/* 已注册级别名称的最大长度 */
+func levelWidth() int {
+	width := 0
+	for _, name := range LogLevelNames {
+		if n := utf8.RuneCountInString(name); n > width {
+			width = n
+		}
+	}
+	return width
+}
+
+func (f *ConsoleFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	var scratch [32]byte
+	f.color(buf, consoleDim, string(e.Time.AppendFormat(scratch[:0], "15:04:05.000")))
+	buf.WriteByte(' ')
+
+	f.color(buf, levelColors[e.Level], padColumn(LogLevelNames[e.Level], levelWidth()))
+	buf.WriteByte(' ')
+
+	width := f.TagWidth
+	if width <= 0 {
+		width = defaultWidth
+	}
+	buf.WriteString(padColumn(lastPath(e.Tag), width))
+	buf.WriteByte(' ')
+
+	if f.ShowFunc && e.Func != "" {
+		f.color(buf, consoleDim, e.Func+":")
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(e.Message)
+
+	for _, field := range e.Fields {
+		buf.WriteByte(' ')
+		f.color(buf, consoleKey, field.Key+"=")
+		buf.WriteString(fieldText(field.Value))
+	}
+	writeStacks(buf, e.Fields)
+}
@@ -0,0 +1,51 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleFormatter(t *testing.T) {
+	e := newEntry(WARNING, "github/com/fpay/fpay/p2p", "Dial", "slow peer", F("peer", "10.0.0.2"), F("rtt", 3*time.Second))
+	e.Time = time.Date(2018, 6, 1, 15, 4, 5, 6e6, time.UTC)
+
+	var buf bytes.Buffer
+	(&ConsoleFormatter{NoColor: true, ShowFunc: true}).Format(&buf, e)
+	if got, want := buf.String(), "15:04:05.006 WARNING p2p          Dial: slow peer peer=10.0.0.2 rtt=3s"; got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	buf.Reset()
+	e.Tag = "averyveryverylongpackage"
+	(&ConsoleFormatter{TagWidth: 8}).Format(&buf, e)
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[2m15:04:05.006\x1b[0m") || !strings.Contains(out, " …package ") || !strings.Contains(out, "\x1b[36mpeer=\x1b[0m") {
+		t.Errorf("colored = %q", out)
+	}
+}
@@ -0,0 +1,251 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+type levelKey struct{}
+
+/* 返回携带日志级别的上下文，经Ctx记录的日志不低于该级别时一律输出 */
+/* 用于单独调试某个请求，如请求头带X-Debug时以VERBOSE级别记录该请求的全部日志 */
+func ContextWithLevel(ctx context.Context, level uint8) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+/* 返回上下文携带的日志级别 */
+func LevelFromContext(ctx context.Context) (uint8, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	level, ok := ctx.Value(levelKey{}).(uint8)
+	return level, ok
+}
+
+/* OpenTracing中opentracing.Span记录日志的方法，避免直接依赖opentracing-go */
+type SpanLogger interface {
+	LogKV(alternatingKeyValues ...interface{})
+}
+
+var spanExtractor atomic.Value /* 从上下文中取出当前span的函数，func(context.Context) SpanLogger */
+
+/* 设置从上下文中取出当前span的函数，之后经Ctx记录的日志同时记录到该span，fn为nil时取消 */
+/* 用法：zlog.SetSpanExtractor(func(ctx context.Context) zlog.SpanLogger { */
+/*     if span := opentracing.SpanFromContext(ctx); span != nil { return span }; return nil }) */
+func SetSpanExtractor(fn func(ctx context.Context) SpanLogger) {
+	spanExtractor.Store(fn)
+}
+
+func spanFromContext(ctx context.Context) SpanLogger {
+	fn, _ := spanExtractor.Load().(func(ctx context.Context) SpanLogger)
+	if fn == nil || ctx == nil {
+		return nil
+	}
+	return fn(ctx)
+}
+
+/* 遵循上下文携带级别的日志句柄 */
+type ContextLogger struct {
+	logger   *Logger
+	level    uint8      /* 上下文携带的级别 */
+	override bool       /* 上下文是否携带级别 */
+	span     SpanLogger /* 上下文中的span，为nil时不记录到span */
+}
+
+/* 返回遵循ctx所携带级别的日志句柄，如 l.Ctx(r.Context()).Debugf(...) */
+func (l *Logger) Ctx(ctx context.Context) *ContextLogger {
+	level, ok := LevelFromContext(ctx)
+	return &ContextLogger{logger: l, level: level, override: ok, span: spanFromContext(ctx)}
+}
+
+/* 返回默认日志记录器上遵循ctx所携带级别的日志句柄 */
+func Ctx(ctx context.Context) *ContextLogger {
+	return std.Ctx(ctx)
+}
+
+/* 判断以指定级别记录的日志是否因上下文携带的级别而强制输出 */
+func (c *ContextLogger) forced(level uint8) bool {
+	return c.override && level >= c.level
+}
+
+func (c *ContextLogger) logf(level uint8, format string, v ...interface{}) {
+	if ci, ok := c.wants(level); ok {
+		msg, fields := sprintf(format, v)
+		c.output(ci.entry(level, msg, fields...))
+	}
+}
+
+func (c *ContextLogger) logln(level uint8, v ...interface{}) {
+	if ci, ok := c.wants(level); ok {
+		c.output(ci.entry(level, strings.TrimSuffix(fmt.Sprintln(resolve(v)...), "\n")))
+	}
+}
+
+func (c *ContextLogger) logw(level uint8, msg string, fields []Field) {
+	if ci, ok := c.wants(level); ok {
+		c.output(ci.entry(level, msg, fields...))
+	}
+}
+
+/* 输出日志，并以event、level、message及各字段记录到上下文中的span，记录的内容已经过脱敏 */
+/* 只为最近日志缓冲或订阅者构造、未实际输出的日志不记录到span */
+func (c *ContextLogger) output(e *Entry) {
+	if !c.logger.emit(e, c.forced(e.Level)) || c.span == nil {
+		return
+	}
+
+	kv := make([]interface{}, 0, 6+2*len(e.Fields))
+	kv = append(kv, "event", "log", "level", LogLevelNames[e.Level], "message", e.Message)
+	for _, f := range e.Fields {
+		kv = append(kv, f.Key, f.Value)
+	}
+	c.span.LogKV(kv...)
+}
+
+/* 解析调用者并判断是否需要构造日志，调用层次须与logf等一致 */
+func (c *ContextLogger) wants(level uint8) (callerInfo, bool) {
+	forced := c.forced(level)
+	if !forced && !c.logger.mayLog(level) {
+		return callerInfo{}, false
+	}
+
+	ci := c.logger.caller(3, 0)
+	return ci, forced || c.logger.wants(level, ci.pkg, ci.file)
+}
+
+func (c *ContextLogger) Logf(level uint8, format string, v ...interface{}) {
+	c.logf(level, format, v...)
+}
+
+func (c *ContextLogger) Logln(level uint8, v ...interface{}) {
+	c.logln(level, v...)
+}
+
+/* 输出带结构化字段的日志，msg不会被当作格式串 */
+func (c *ContextLogger) Logw(level uint8, msg string, fields ...Field) {
+	c.logw(level, msg, fields)
+}
+
+func (c *ContextLogger) Verbosef(format string, v ...interface{}) {
+	c.logf(VERBOSE, format, v...)
+}
+
+func (c *ContextLogger) Verboseln(v ...interface{}) {
+	c.logln(VERBOSE, v...)
+}
+
+func (c *ContextLogger) Tracef(format string, v ...interface{}) {
+	c.logf(TRACE, format, v...)
+}
+
+func (c *ContextLogger) Traceln(v ...interface{}) {
+	c.logln(TRACE, v...)
+}
+
+func (c *ContextLogger) Debugf(format string, v ...interface{}) {
+	c.logf(DEBUG, format, v...)
+}
+
+func (c *ContextLogger) Debugln(v ...interface{}) {
+	c.logln(DEBUG, v...)
+}
+
+func (c *ContextLogger) Infof(format string, v ...interface{}) {
+	c.logf(INFO, format, v...)
+}
+
+func (c *ContextLogger) Infoln(v ...interface{}) {
+	c.logln(INFO, v...)
+}
+
+func (c *ContextLogger) Warningf(format string, v ...interface{}) {
+	c.logf(WARNING, format, v...)
+}
+
+func (c *ContextLogger) Warningln(v ...interface{}) {
+	c.logln(WARNING, v...)
+}
+
+func (c *ContextLogger) Errorf(format string, v ...interface{}) {
+	c.logf(ERROR, format, v...)
+}
+
+func (c *ContextLogger) Errorln(v ...interface{}) {
+	c.logln(ERROR, v...)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (c *ContextLogger) Fatalf(format string, v ...interface{}) {
+	c.logf(FATAL, format, v...)
+	c.logger.Sync()
+	Exit(c.logger.fatalCode())
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (c *ContextLogger) Fatalln(v ...interface{}) {
+	c.logln(FATAL, v...)
+	c.logger.Sync()
+	Exit(c.logger.fatalCode())
+}
+
+/* 以VERBOSE级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Verbose(msg string, fields ...Field) {
+	c.logw(VERBOSE, msg, fields)
+}
+
+/* 以TRACE级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Trace(msg string, fields ...Field) {
+	c.logw(TRACE, msg, fields)
+}
+
+/* 以DEBUG级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Debug(msg string, fields ...Field) {
+	c.logw(DEBUG, msg, fields)
+}
+
+/* 以INFO级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Info(msg string, fields ...Field) {
+	c.logw(INFO, msg, fields)
+}
+
+/* 以WARNING级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Warning(msg string, fields ...Field) {
+	c.logw(WARNING, msg, fields)
+}
+
+/* 以ERROR级别输出日志，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Error(msg string, fields ...Field) {
+	c.logw(ERROR, msg, fields)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程，msg原样输出，不会被当作格式串 */
+func (c *ContextLogger) Fatal(msg string, fields ...Field) {
+	c.logw(FATAL, msg, fields)
+	c.logger.Sync()
+	Exit(c.logger.fatalCode())
+}
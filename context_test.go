@@ -0,0 +1,127 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContextLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(INFO)
+
+	debug := ContextWithLevel(context.Background(), VERBOSE)
+	if level, ok := LevelFromContext(debug); !ok || level != VERBOSE {
+		t.Errorf("LevelFromContext = %d, %v", level, ok)
+	}
+
+	l.Ctx(context.Background()).Debugln("plain")
+	l.Ctx(debug).Verboseln("forced")
+	l.Ctx(debug).Infof("info %d", 1)
+
+	out := buf.String()
+	if strings.Contains(out, "plain") {
+		t.Errorf("unexpected plain in %q", out)
+	}
+	for _, want := range []string{"[zlog: TestContextLevel] forced", "info 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+}
+
+/* 记录LogKV参数的span */
+type fakeSpan struct {
+	kv [][]interface{}
+}
+
+func (s *fakeSpan) LogKV(kv ...interface{}) {
+	s.kv = append(s.kv, kv)
+}
+
+type spanKey struct{}
+
+func TestSpanLogger(t *testing.T) {
+	SetSpanExtractor(func(ctx context.Context) SpanLogger {
+		span, _ := ctx.Value(spanKey{}).(*fakeSpan)
+		if span == nil {
+			return nil
+		}
+		return span
+	})
+	defer SetSpanExtractor(nil)
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(INFO)
+	l.RedactKeys("token")
+
+	span := new(fakeSpan)
+	ctx := context.WithValue(context.Background(), spanKey{}, span)
+	l.Ctx(ctx).Logw(WARNING, "slow query", F("ms", 250), F("token", "secret"))
+	l.Ctx(ctx).Debugln("hidden")
+	l.Ctx(context.Background()).Infoln("no span")
+
+	/* 记录最近日志时低级别的日志也会被构造，但不应记录到span */
+	SetRecentSize(10)
+	l.Ctx(ctx).Debugln("recorded only")
+	SetRecentSize(0)
+
+	if len(span.kv) != 1 {
+		t.Fatalf("span logs: %v", span.kv)
+	}
+	if got := fmt.Sprint(span.kv[0]); got != "[event log level WARNING message slow query ms 250 token ***]" {
+		t.Errorf("span kv: %s", got)
+	}
+	if !strings.Contains(buf.String(), "slow query") {
+		t.Errorf("entry not logged: %q", buf.String())
+	}
+}
+
+func TestLevelHeader(t *testing.T) {
+	var got []bool
+	h := LevelHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := LevelFromContext(r.Context())
+		got = append(got, ok)
+	}), "X-Debug", VERBOSE)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Debug", "1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(got) != 2 || got[0] || !got[1] {
+		t.Errorf("got %v, want [false true]", got)
+	}
+}
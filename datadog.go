@@ -0,0 +1,124 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+/* Datadog日志采集识别的JSON格式，字段trace_id、span_id输出为dd.trace_id、dd.span_id以关联APM调用链 */
+/* 没有span_id时以TraceContext的parent_id作为dd.span_id，十六进制的ID转换为Datadog使用的十进制 */
+/* 字段error输出为error.message、error.kind及error.stack */
+type DatadogFormatter struct {
+	Service string /* 服务名，即service属性 */
+	Source  string /* 日志来源，即ddsource属性，为空时为"go" */
+	Env     string /* 部署环境，不为空时输出env属性 */
+	Version string /* 应用版本，不为空时输出version属性 */
+}
+
+/* 将W3C traceparent中32位或16位小写十六进制的ID转换为其低64位的十进制字符串，其他值不变 */
+func datadogID(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || !isLowerHex(s, 32) && !isLowerHex(s, 16) {
+		return jsonValue(v)
+	}
+
+	n, _ := strconv.ParseUint(s[len(s)-16:], 16, 64)
+	return strconv.FormatUint(n, 10)
+}
+
+/* 级别对应的Datadog status，自定义级别按所在区间对应 */
+func datadogStatus(level uint8) string {
+	switch {
+	case level < INFO:
+		return "debug"
+	case level < WARNING:
+		return "info"
+	case level < ERROR:
+		return "warn"
+	case level < FATAL:
+		return "error"
+	default:
+		return "critical"
+	}
+}
+
+func (f *DatadogFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	m := make(map[string]interface{}, len(e.Fields)+8)
+	for _, field := range e.Fields {
+		switch field.Key {
+		case "trace_id", "span_id":
+			m["dd."+field.Key] = datadogID(field.Value)
+		case "parent_id":
+			if _, ok := m["dd.span_id"]; !ok {
+				m["dd.span_id"] = datadogID(field.Value)
+			}
+		case "error":
+			err, ok := field.Value.(error)
+			if !ok || err == nil {
+				m[field.Key] = jsonValue(field.Value)
+				continue
+			}
+
+			chain := ErrorChain(err)
+			m["error.message"] = chain[0].Message
+			m["error.kind"] = chain[len(chain)-1].Type
+			for _, info := range chain {
+				if info.Stack != "" {
+					m["error.stack"] = info.Stack
+				}
+			}
+		default:
+			m[field.Key] = jsonValue(field.Value)
+		}
+	}
+
+	source := f.Source
+	if source == "" {
+		source = "go"
+	}
+	m["message"] = e.Message
+	m["status"] = datadogStatus(e.Level)
+	m["timestamp"] = e.Time
+	m["ddsource"] = source
+	m["logger.name"] = e.Tag
+	m["logger.method_name"] = e.Func
+	if f.Service != "" {
+		m["service"] = f.Service
+	}
+	if f.Env != "" {
+		m["env"] = f.Env
+	}
+	if f.Version != "" {
+		m["version"] = f.Version
+	}
+
+	if err := json.NewEncoder(buf).Encode(m); err != nil {
+		b, _ := json.Marshal("zlog: " + err.Error())
+		buf.WriteString(`{"status":"error","message":`)
+		buf.Write(b)
+		buf.WriteString("}\n")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDatadogFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&DatadogFormatter{Service: "gateway", Env: "prod"})
+
+	err := fmt.Errorf("read config: %w", io.ErrUnexpectedEOF)
+	l.Errorw("startup failed", err, F("trace_id", uint64(1234)), F("port", 8080))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]interface{}{
+		"message":            "startup failed",
+		"status":             "error",
+		"service":            "gateway",
+		"env":                "prod",
+		"ddsource":           "go",
+		"dd.trace_id":        float64(1234),
+		"port":               float64(8080),
+		"error.message":      "read config: unexpected EOF",
+		"error.kind":         "*errors.errorString",
+		"logger.method_name": "TestDatadogFormatter",
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	if _, ok := got["version"]; ok {
+		t.Error("empty version should be omitted")
+	}
+}
+
+func TestDatadogTraceparentIDs(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&DatadogFormatter{})
+
+	tc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Logw(INFO, "request", tc.Fields()...)
+	l.Logw(INFO, "explicit span", F("parent_id", "00f067aa0ba902b7"), F("span_id", uint64(42)))
+
+	dec := json.NewDecoder(&buf)
+	var got map[string]interface{}
+	if err := dec.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	/* 0xa3ce929d0e0e4736及0x00f067aa0ba902b7 */
+	if got["dd.trace_id"] != "11803532876627986230" || got["dd.span_id"] != "67667974448284343" {
+		t.Errorf("ids = %v, %v", got["dd.trace_id"], got["dd.span_id"])
+	}
+
+	got = nil
+	if err := dec.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["dd.span_id"] != float64(42) {
+		t.Errorf("span_id should take precedence over parent_id, got %v", got["dd.span_id"])
+	}
+}
@@ -0,0 +1,273 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+var dumpLimit int64 = 4096 /* Dumpf最多输出的字节数 */
+
+/* 设置Dumpf最多输出的字节数，超出部分被截断 */
+func SetDumpLimit(limit int) {
+	atomic.StoreInt64(&dumpLimit, int64(limit))
+}
+
+/* 生成对齐的十六进制及ASCII对照，超出限制的部分以截断说明代替 */
+func hexDump(data []byte) string {
+	limit := int(atomic.LoadInt64(&dumpLimit))
+	if limit < 0 || limit >= len(data) {
+		return strings.TrimSuffix(hex.Dump(data), "\n")
+	}
+
+	return hex.Dump(data[:limit]) + fmt.Sprintf("... %d bytes truncated", len(data)-limit)
+}
+
+/* 以十六进制及ASCII对照输出字节数据，仅在级别启用时才生成内容，用于调试二进制协议 */
+func (l *Logger) Dumpf(level uint8, label string, data []byte) {
+	l.logf(0, level, "%s (%d bytes):\n%s", label, len(data), Lazy(func() string { return hexDump(data) }))
+}
+
+/* 以十六进制及ASCII对照输出字节数据，仅在级别启用时才生成内容，用于调试二进制协议 */
+func Dumpf(level uint8, label string, data []byte) {
+	std.logf(0, level, "%s (%d bytes):\n%s", label, len(data), Lazy(func() string { return hexDump(data) }))
+}
+
+/* 生成各列对齐的表格，表头下方以-分隔，行中多出的列被忽略，缺少的列留空 */
+func renderTable(headers []string, rows [][]string) string {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	writeRow := func(cells []string) {
+		start := buf.Len()
+		for i, w := range widths {
+			var cell string
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if i > 0 {
+				buf.WriteString("  ")
+			}
+			buf.WriteString(cell)
+			buf.WriteString(strings.Repeat(" ", w-utf8.RuneCountInString(cell)))
+		}
+		buf.Truncate(start + len(bytes.TrimRight(buf.Bytes()[start:], " ")))
+	}
+
+	writeRow(headers)
+	dashes := make([]string, len(widths))
+	for i, w := range widths {
+		dashes[i] = strings.Repeat("-", w)
+	}
+	buf.WriteByte('\n')
+	writeRow(dashes)
+	for _, row := range rows {
+		buf.WriteByte('\n')
+		writeRow(row)
+	}
+	return buf.String()
+}
+
+/* 以对齐的表格输出数据(如节点列表、配置)，整个表格为一条多行日志，仅在级别启用时才生成内容 */
+func (l *Logger) Table(level uint8, headers []string, rows [][]string) {
+	l.logf(0, level, "table (%d rows):\n%s", len(rows), Lazy(func() string { return renderTable(headers, rows) }))
+}
+
+/* 以对齐的表格输出数据，整个表格为一条多行日志，仅在级别启用时才生成内容 */
+func Table(level uint8, headers []string, rows [][]string) {
+	std.logf(0, level, "table (%d rows):\n%s", len(rows), Lazy(func() string { return renderTable(headers, rows) }))
+}
+
+const maxPrettyDepth = 10 /* Pretty展开的最大嵌套层数 */
+
+/* 以缩进形式展开结构体、map、切片及指针，用于DEBUG级别查看复杂数据 */
+func Pretty(v interface{}) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	writePretty(buf, reflect.ValueOf(v), 0, map[uintptr]bool{})
+	return buf.String()
+}
+
+func writeIndent(buf *bytes.Buffer, depth int) {
+	for i := 0; i < depth; i++ {
+		buf.WriteString("    ")
+	}
+}
+
+/* 优先使用error及fmt.Stringer的输出，方法panic时退回结构展开 */
+func writeMethod(buf *bytes.Buffer, v reflect.Value) (ok bool) {
+	if !v.CanInterface() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return false
+	}
+
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	switch m := v.Interface().(type) {
+	case error:
+		buf.WriteString(m.Error())
+	case fmt.Stringer:
+		buf.WriteString(m.String())
+	default:
+		return false
+	}
+	return true
+}
+
+func writePretty(buf *bytes.Buffer, v reflect.Value, depth int, visited map[uintptr]bool) {
+	if !v.IsValid() {
+		buf.WriteString("nil")
+		return
+	}
+
+	if depth > maxPrettyDepth {
+		buf.WriteString("...")
+		return
+	}
+
+	if writeMethod(buf, v) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			buf.WriteString("nil")
+			return
+		}
+		if visited[v.Pointer()] {
+			buf.WriteString("<cycle>")
+			return
+		}
+		visited[v.Pointer()] = true
+		buf.WriteByte('&')
+		writePretty(buf, v.Elem(), depth, visited)
+		delete(visited, v.Pointer())
+
+	case reflect.Interface:
+		writePretty(buf, v.Elem(), depth, visited)
+
+	case reflect.Struct:
+		buf.WriteString(v.Type().String())
+		buf.WriteString("{\n")
+		for i := 0; i < v.NumField(); i++ {
+			writeIndent(buf, depth+1)
+			buf.WriteString(v.Type().Field(i).Name)
+			buf.WriteString(": ")
+			writePretty(buf, v.Field(i), depth+1, visited)
+			buf.WriteString(",\n")
+		}
+		writeIndent(buf, depth)
+		buf.WriteByte('}')
+
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("nil")
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		buf.WriteString(v.Type().String())
+		buf.WriteString("{\n")
+		for _, key := range keys {
+			writeIndent(buf, depth+1)
+			writePretty(buf, key, depth+1, visited)
+			buf.WriteString(": ")
+			writePretty(buf, v.MapIndex(key), depth+1, visited)
+			buf.WriteString(",\n")
+		}
+		writeIndent(buf, depth)
+		buf.WriteByte('}')
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("nil")
+			return
+		}
+		buf.WriteString(v.Type().String())
+		if v.Len() == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i := 0; i < v.Len(); i++ {
+			writeIndent(buf, depth+1)
+			writePretty(buf, v.Index(i), depth+1, visited)
+			buf.WriteString(",\n")
+		}
+		writeIndent(buf, depth)
+		buf.WriteByte('}')
+
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+
+	default:
+		fmt.Fprint(buf, v)
+	}
+}
+
+/* 将参数替换为延迟展开的Pretty结果 */
+func prettyArgs(v []interface{}) []interface{} {
+	args := make([]interface{}, len(v))
+	for i, arg := range v {
+		arg := arg
+		args[i] = Lazy(func() string { return Pretty(arg) })
+	}
+	return args
+}
+
+/* 以Pretty展开参数后按format输出，仅在级别启用时才展开，format中应使用%s或%v */
+func (l *Logger) Spewf(level uint8, format string, v ...interface{}) {
+	l.logf(0, level, format, prettyArgs(v)...)
+}
+
+/* 以Pretty展开参数后按format输出，仅在级别启用时才展开，format中应使用%s或%v */
+func Spewf(level uint8, format string, v ...interface{}) {
+	std.logf(0, level, format, prettyArgs(v)...)
+}
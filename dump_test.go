@@ -0,0 +1,114 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpf(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	SetDumpLimit(16)
+	defer SetDumpLimit(4096)
+
+	l.Dumpf(DEBUG, "handshake", []byte("GET / HTTP/1.1\r\nHost: fpay\r\n"))
+	out := buf.String()
+	if !strings.Contains(out, "handshake (28 bytes):\n00000000  47 45 54 20") {
+		t.Errorf("unexpected dump: %q", out)
+	}
+	if !strings.Contains(out, "|GET / HTTP/1.1..|") || !strings.Contains(out, "... 12 bytes truncated") {
+		t.Errorf("unexpected dump: %q", out)
+	}
+
+	buf.Reset()
+	l.SetLevel(INFO)
+	l.Dumpf(DEBUG, "hidden", []byte{1, 2, 3})
+	if buf.Len() != 0 {
+		t.Errorf("disabled dump written: %q", buf.String())
+	}
+}
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true, Multiline: MultilineIndent})
+
+	l.Table(INFO, []string{"PEER", "PORT", "STATE"}, [][]string{
+		{"10.0.0.2", "8080", "连接"},
+		{"10.0.0.10", "443"},
+	})
+
+	want := "] table (2 rows):\n" +
+		"\tPEER       PORT  STATE\n" +
+		"\t---------  ----  -----\n" +
+		"\t10.0.0.2   8080  连接\n" +
+		"\t10.0.0.10  443\n"
+	if out := buf.String(); !strings.HasSuffix(out, want) {
+		t.Errorf("got %q\nwant suffix %q", out, want)
+	}
+}
+
+type peer struct {
+	Addr  string
+	Port  int
+	Tags  map[string]int
+	Next  *peer
+	Ids   []uint8
+	inner *peer
+}
+
+func TestPretty(t *testing.T) {
+	p := &peer{Addr: "10.0.0.1", Port: 30303, Tags: map[string]int{"b": 2, "a": 1}}
+	p.Next = p
+
+	want := `&zlog.peer{
+    Addr: "10.0.0.1",
+    Port: 30303,
+    Tags: map[string]int{
+        "a": 1,
+        "b": 2,
+    },
+    Next: <cycle>,
+    Ids: nil,
+    inner: nil,
+}`
+	if got := Pretty(p); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.Spewf(DEBUG, "state: %v", []int{1})
+	if !strings.Contains(buf.String(), "state: []int{\n    1,\n}") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
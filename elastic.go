@@ -0,0 +1,199 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* Elasticsearch及OpenSearch批量索引的配置 */
+type ElasticConfig struct {
+	URL        string        /* 集群地址，如http://es:9200 */
+	Index      string        /* 索引名前缀，按日期索引为 前缀-2006.01.02，默认为zlog */
+	Header     http.Header   /* 附加的请求头，如Authorization */
+	BatchSize  int           /* 每批最多的条数，默认100 */
+	Interval   time.Duration /* 定时发送的间隔，默认1秒 */
+	Retries    int           /* 请求失败或部分文档被429拒绝后的重试次数 */
+	Backoff    time.Duration /* 首次重试前的等待时间，之后每次加倍，默认1秒 */
+	MaxBackoff time.Duration /* 等待时间的上限，默认30秒 */
+	Client     *http.Client  /* 为nil时使用http.DefaultClient */
+	Spill      *SpillFile    /* 不为nil时发送最终失败或队列已满的日志写入该暂存文件，否则丢弃 */
+}
+
+/* 通过_bulk接口批量索引到Elasticsearch的输出目标，每天使用一个索引 */
+type ElasticWriter struct {
+	cfg   ElasticConfig
+	batch *batcher
+}
+
+func NewElasticWriter(cfg ElasticConfig) *ElasticWriter {
+	if cfg.Index == "" {
+		cfg.Index = "zlog"
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	w := &ElasticWriter{cfg: cfg}
+	w.batch = newBatcher(cfg.BatchSize, cfg.Interval, cfg.Spill, w.bulk)
+	return w
+}
+
+/* 索引的文档 */
+type elasticDoc struct {
+	Timestamp time.Time              `json:"@timestamp"`
+	Level     string                 `json:"level"`
+	Tag       string                 `json:"tag,omitempty"`
+	Func      string                 `json:"func,omitempty"`
+	File      string                 `json:"file,omitempty"`
+	Line      int                    `json:"line,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+func (w *ElasticWriter) WriteEntry(e *Entry) error {
+	doc := elasticDoc{
+		Timestamp: e.Time,
+		Level:     LogLevelNames[e.Level],
+		Tag:       e.Tag,
+		Func:      e.Func,
+		File:      e.File,
+		Line:      e.Line,
+		Message:   e.Message,
+		Fields:    fieldsMap(e.Fields),
+	}
+
+	line, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return w.batch.add(batchItem{time: e.Time, level: e.Level, tag: e.Tag, line: line})
+}
+
+/* 未经Logger写入的内容按行作为message索引 */
+func (w *ElasticWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		b, _ := json.Marshal(elasticDoc{Timestamp: now, Message: line})
+		if aerr := w.batch.add(batchItem{time: now, level: SILENCE, line: b}); err == nil {
+			err = aerr
+		}
+	}
+	return len(p), err
+}
+
+/* 重新发送暂存文件中的日志，应在远端恢复后调用 */
+func (w *ElasticWriter) Replay() error {
+	return w.batch.replay()
+}
+
+/* 等待发送的日志条数 */
+func (w *ElasticWriter) QueueDepth() int {
+	return w.batch.queueDepth()
+}
+
+/* 发送缓冲的日志并等待完成 */
+func (w *ElasticWriter) Flush() error {
+	return w.batch.flush()
+}
+
+/* 发送剩余的日志并停止定时发送 */
+func (w *ElasticWriter) Close() error {
+	return w.batch.close()
+}
+
+/* _bulk响应中需要的部分 */
+type elasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func (w *ElasticWriter) bulk(items []batchItem) error {
+	header := http.Header{"Content-Type": {"application/x-ndjson"}}
+	for k, vs := range w.cfg.Header {
+		header[k] = vs
+	}
+
+	url := strings.TrimSuffix(w.cfg.URL, "/") + "/_bulk"
+	backoff := w.cfg.Backoff
+	for i := 0; ; i++ {
+		var body bytes.Buffer
+		for _, item := range items {
+			fmt.Fprintf(&body, `{"index":{"_index":"%s-%s"}}`+"\n", w.cfg.Index, item.time.UTC().Format("2006.01.02"))
+			body.Write(item.line)
+			body.WriteByte('\n')
+		}
+
+		b, err := postWithRetry(w.cfg.Client, url, header, body.Bytes(), w.cfg.Retries, w.cfg.Backoff, w.cfg.MaxBackoff)
+		if err != nil {
+			return err
+		}
+
+		var resp elasticBulkResponse
+		if err := json.Unmarshal(b, &resp); err != nil {
+			return fmt.Errorf("zlog: %s: %v", url, err)
+		}
+		if !resp.Errors {
+			return nil
+		}
+
+		/* 只重发被429拒绝的文档，其他错误不可恢复 */
+		var retry []batchItem
+		var failure error
+		for j, result := range resp.Items {
+			for _, r := range result {
+				switch {
+				case r.Status == http.StatusTooManyRequests && j < len(items):
+					retry = append(retry, items[j])
+				case r.Status >= 300 && failure == nil:
+					failure = fmt.Errorf("zlog: %s: %d %s: %s", url, r.Status, r.Error.Type, r.Error.Reason)
+				}
+			}
+		}
+
+		if len(retry) == 0 || i >= w.cfg.Retries {
+			if failure == nil && len(retry) > 0 {
+				failure = fmt.Errorf("zlog: %s: %d documents rejected with 429", url, len(retry))
+			}
+			return failure
+		}
+
+		items = retry
+		backoff = sleepBackoff(backoff, w.cfg.MaxBackoff)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElasticWriter(t *testing.T) {
+	var mu sync.Mutex
+	var indexed []string
+	rejected := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+
+		var statuses []string
+		scanner := bufio.NewScanner(r.Body)
+		for n := 0; scanner.Scan(); n++ {
+			if n%2 == 0 {
+				if !strings.HasPrefix(scanner.Text(), `{"index":{"_index":"app-`) {
+					t.Errorf("action = %s", scanner.Text())
+				}
+				continue
+			}
+
+			var doc elasticDoc
+			json.Unmarshal(scanner.Bytes(), &doc)
+			status := 201
+			if doc.Message == "second" && !rejected {
+				rejected, status = true, 429
+			} else {
+				indexed = append(indexed, doc.Message)
+			}
+			statuses = append(statuses, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+		}
+		fmt.Fprintf(w, `{"errors":%v,"items":[%s]}`, rejected && len(indexed) < 3, strings.Join(statuses, ","))
+	}))
+	defer srv.Close()
+
+	ew := NewElasticWriter(ElasticConfig{URL: srv.URL, Index: "app", Interval: time.Hour, Retries: 2, Backoff: time.Millisecond})
+	l := NewLogger()
+	l.SetOutput(ew)
+	l.Infoln("first")
+	l.Infoln("second")
+	l.Logw(WARNING, "third", F("shard", 3))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(indexed, ",") != "first,third,second" {
+		t.Errorf("indexed = %v", indexed)
+	}
+}
@@ -0,0 +1,130 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+const maxEncryptedFrame = 16 << 20 /* 解密时允许的最大记录长度，避免损坏的长度头导致过量分配 */
+
+/* 使用AES-GCM加密落盘的输出目标，每次Write加密为一条记录：4字节大端序长度、12字节随机nonce、密文 */
+/* 可使用DecryptLog还原 */
+type EncryptedWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	aead cipher.AEAD
+}
+
+/* 包装w为加密输出目标，key长度为16、24或32字节，分别对应AES-128、AES-192、AES-256 */
+func NewEncryptedWriter(w io.Writer, key []byte) (*EncryptedWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedWriter{w: w, aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *EncryptedWriter) Write(p []byte) (int, error) {
+	size := e.aead.NonceSize() + len(p) + e.aead.Overhead()
+	frame := make([]byte, 4+e.aead.NonceSize(), 4+size)
+	binary.BigEndian.PutUint32(frame, uint32(size))
+	nonce := frame[4:]
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, err
+	}
+	frame = e.aead.Seal(frame, nonce, p, nil)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.w.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+/* 写出被包装输出目标缓冲的内容 */
+func (e *EncryptedWriter) Flush() error {
+	return flushWriter(e.w)
+}
+
+/* 落盘被包装的输出目标 */
+func (e *EncryptedWriter) Sync() error {
+	return syncWriter(e.w)
+}
+
+/* 关闭被包装的输出目标，标准输出及标准错误不会被关闭 */
+func (e *EncryptedWriter) Close() error {
+	return closeWriter(e.w)
+}
+
+var errEncryptedFrame = errors.New("zlog: malformed encrypted log frame")
+
+/* 解密EncryptedWriter写入的日志并写入w，末尾不完整的记录视为错误 */
+func DecryptLog(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	var head [4]byte
+	for {
+		if _, err := io.ReadFull(r, head[:]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errEncryptedFrame
+		}
+
+		size := binary.BigEndian.Uint32(head[:])
+		if size < uint32(aead.NonceSize()+aead.Overhead()) || size > maxEncryptedFrame {
+			return errEncryptedFrame
+		}
+
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return errEncryptedFrame
+		}
+
+		plain, err := aead.Open(frame[aead.NonceSize():aead.NonceSize()], frame[:aead.NonceSize()], frame[aead.NonceSize():], nil)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+	}
+}
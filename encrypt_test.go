@@ -0,0 +1,68 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedWriter(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	var sealed bytes.Buffer
+	w, err := NewEncryptedWriter(&sealed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLogger()
+	l.SetOutput(w)
+	l.Infoln("card issued")
+	l.Warningln("tamper switch")
+
+	if strings.Contains(sealed.String(), "card issued") {
+		t.Fatal("plaintext found in encrypted output")
+	}
+
+	var plain bytes.Buffer
+	if err := DecryptLog(&plain, bytes.NewReader(sealed.Bytes()), key); err != nil {
+		t.Fatal(err)
+	}
+	out := plain.String()
+	if !strings.Contains(out, "] card issued\n") || !strings.Contains(out, "] tamper switch\n") {
+		t.Errorf("decrypted %q", out)
+	}
+
+	if err := DecryptLog(&plain, bytes.NewReader(sealed.Bytes()), bytes.Repeat([]byte{8}, 32)); err == nil {
+		t.Error("expected error with wrong key")
+	}
+	if err := DecryptLog(&plain, bytes.NewReader(sealed.Bytes()[:sealed.Len()-1]), key); err == nil {
+		t.Error("expected error with truncated input")
+	}
+	if _, err := NewEncryptedWriter(&sealed, []byte("short")); err == nil {
+		t.Error("expected error with invalid key size")
+	}
+}
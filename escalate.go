@@ -0,0 +1,85 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* 一个调用处在当前窗口内的计数 */
+type escalation struct {
+	start time.Time /* 窗口开始时间，即窗口内的首条日志 */
+	n     int
+}
+
+/* 返回升级规则的中间环节：同一调用处的level级别日志在window内超过threshold条时，额外输出一条to级别的汇总 */
+/* 窗口从首条日志开始计时，每个窗口最多汇总一次，如 zlog.Use(zlog.Escalate(WARNING, 50, 5*time.Minute, ERROR)) */
+func Escalate(level uint8, threshold int, window time.Duration, to uint8) Middleware {
+	var (
+		mu    sync.Mutex
+		sites = make(map[sampleKey]*escalation)
+	)
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			next.Handle(e)
+			if e.Level != level {
+				return
+			}
+
+			key := sampleKey{file: e.File, line: e.Line}
+			if e.File == "" {
+				key.msg = e.Message
+			}
+
+			mu.Lock()
+			s, ok := sites[key]
+			if !ok || e.Time.Sub(s.start) >= window {
+				/* 顺带清理过期的调用处，避免长期运行时无限增长 */
+				for k, v := range sites {
+					if e.Time.Sub(v.start) >= window {
+						delete(sites, k)
+					}
+				}
+				s = &escalation{start: e.Time}
+				sites[key] = s
+			}
+			s.n++
+			n := s.n
+			mu.Unlock()
+
+			if n != threshold+1 {
+				return
+			}
+
+			summary := *e
+			summary.Level = to
+			summary.Time = time.Now()
+			summary.Message = LogLevelNames[level] + " repeated " + strconv.Itoa(n) + " times within " + window.String() + ": " + e.Message
+			summary.Fields = []Field{F("escalated_from", LogLevelNames[level]), F("count", n)}
+			next.Handle(&summary)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalate(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.Use(Escalate(WARNING, 3, time.Minute, ERROR))
+
+	for i := 0; i < 10; i++ {
+		l.Warningln("disk almost full")
+	}
+	l.Warningln("elsewhere")
+
+	out := buf.String()
+	if n := strings.Count(out, "[ERROR]"); n != 1 {
+		t.Fatalf("expected one summary, got %d in %q", n, out)
+	}
+	if !strings.Contains(out, "WARNING repeated 4 times within 1m0s: disk almost full escalated_from=WARNING count=4") {
+		t.Errorf("summary: %q", out)
+	}
+	if n := strings.Count(out, "[WARNING]"); n != 11 {
+		t.Errorf("originals: %d", n)
+	}
+}
@@ -0,0 +1,113 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	exitMu       sync.Mutex
+	exitHandlers []func()                        /* Fatal退出进程前依次执行的处理函数 */
+	exitTimeout  time.Duration = 5 * time.Second /* 处理函数的总执行时限 */
+	osExit                     = os.Exit         /* 测试时可替换 */
+)
+
+/* 注册Fatal退出进程前执行的处理函数，如关闭数据库、上报链路追踪数据 */
+func RegisterExitHandler(handler func()) {
+	exitMu.Lock()
+	exitHandlers = append(exitHandlers, handler)
+	exitMu.Unlock()
+}
+
+/* 设置退出处理函数的总执行时限，超时后不再等待直接退出 */
+func SetExitTimeout(timeout time.Duration) {
+	exitMu.Lock()
+	exitTimeout = timeout
+	exitMu.Unlock()
+}
+
+/* 执行退出处理函数并写出缓冲的日志后以code退出进程 */
+func Exit(code int) {
+	exitMu.Lock()
+	handlers := append([]func(){}, exitHandlers...)
+	timeout := exitTimeout
+	exitMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, handler := range handlers {
+			runExitHandler(handler)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+
+	Flush()
+	osExit(code)
+}
+
+/* 设置Fatal系列方法退出进程时的退出码，默认为1，便于运维工具区分配置错误与崩溃 */
+func (l *Logger) SetExitCode(code int) {
+	atomic.StoreInt32(&l.exitCode, int32(code))
+}
+
+func (l *Logger) fatalCode() int {
+	if code := atomic.LoadInt32(&l.exitCode); code != 0 {
+		return int(code)
+	}
+	return 1
+}
+
+/* 输出FATAL日志后执行退出处理函数并以code退出进程 */
+func (l *Logger) FatalExitf(code int, format string, v ...interface{}) {
+	l.logf(0, FATAL, format, v...)
+	l.Sync()
+	Exit(code)
+}
+
+/* 设置默认日志记录器的Fatal系列函数退出进程时的退出码，默认为1 */
+func SetExitCode(code int) {
+	std.SetExitCode(code)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以code退出进程 */
+func FatalExitf(code int, format string, v ...interface{}) {
+	std.logf(0, FATAL, format, v...)
+	Exit(code)
+}
+
+/* 单个处理函数panic时不影响其余处理函数执行 */
+func runExitHandler(handler func()) {
+	defer func() {
+		recover()
+	}()
+	handler()
+}
@@ -0,0 +1,91 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExitHandlers(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() {
+		osExit = os.Exit
+		exitHandlers = nil
+		exitTimeout = 5 * time.Second
+	}()
+
+	var order []int
+	RegisterExitHandler(func() { order = append(order, 1) })
+	RegisterExitHandler(func() { panic("broken handler") })
+	RegisterExitHandler(func() { order = append(order, 3) })
+
+	l := NewLogger()
+	l.SetOutput(&bytes.Buffer{})
+	l.Fatalln("shutting down")
+	if code != 1 || len(order) != 2 || order[0] != 1 || order[1] != 3 {
+		t.Fatalf("code=%d order=%v", code, order)
+	}
+
+	SetExitTimeout(10 * time.Millisecond)
+	RegisterExitHandler(func() { time.Sleep(time.Second) })
+	start := time.Now()
+	Exit(2)
+	if code != 2 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("timeout not honored: code=%d elapsed=%v", code, time.Since(start))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	var buf bytes.Buffer
+	l := New(WithOutput(&buf), WithFormat(&TextFormatter{NoColor: true}), WithExitCode(78))
+	l.Fatal("bad config")
+	if code != 78 {
+		t.Errorf("code = %d, want 78", code)
+	}
+
+	l.Tagged("cfg").Fatalf("still %s", "bad")
+	if code != 78 {
+		t.Errorf("tagged code = %d, want 78", code)
+	}
+
+	l.FatalExitf(3, "crashed after %d tries", 5)
+	if code != 3 || !bytes.Contains(buf.Bytes(), []byte("[FATAL][zlog: TestExitCode] crashed after 5 tries")) {
+		t.Errorf("code = %d, output %q", code, buf.String())
+	}
+
+	l.SetExitCode(0)
+	l.Fatalln("default")
+	if code != 1 {
+		t.Errorf("code = %d, want default 1", code)
+	}
+}
@@ -0,0 +1,101 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+/* 依次尝试各输出目标直至写入成功的输出目标，用于高可用的日志收集 */
+type FailoverWriter struct {
+	writers []io.Writer
+	served  []uint64 /* 各输出目标成功写入的次数，原子读写 */
+	failed  uint64   /* 所有输出目标均失败的次数 */
+}
+
+/* 按顺序组合输出目标，每次写入从第一个开始尝试 */
+func Failover(writers ...io.Writer) *FailoverWriter {
+	return &FailoverWriter{writers: writers, served: make([]uint64, len(writers))}
+}
+
+func (f *FailoverWriter) Write(p []byte) (int, error) {
+	err := io.ErrClosedPipe
+	for i, w := range f.writers {
+		var n int
+		if n, err = w.Write(p); err == nil {
+			atomic.AddUint64(&f.served[i], 1)
+			return n, nil
+		}
+	}
+
+	atomic.AddUint64(&f.failed, 1)
+	return 0, err
+}
+
+/* 返回各输出目标成功写入的次数，下标与Failover的参数一致 */
+func (f *FailoverWriter) Served() []uint64 {
+	served := make([]uint64, len(f.served))
+	for i := range f.served {
+		served[i] = atomic.LoadUint64(&f.served[i])
+	}
+	return served
+}
+
+/* 返回所有输出目标均失败的次数 */
+func (f *FailoverWriter) Failed() uint64 {
+	return atomic.LoadUint64(&f.failed)
+}
+
+/* 写出各输出目标缓冲的内容 */
+func (f *FailoverWriter) Flush() error {
+	var err error
+	for _, w := range f.writers {
+		if ferr := flushWriter(w); err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
+
+/* 落盘各输出目标 */
+func (f *FailoverWriter) Sync() error {
+	var err error
+	for _, w := range f.writers {
+		if serr := syncWriter(w); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+/* 关闭各输出目标，标准输出及标准错误不会被关闭 */
+func (f *FailoverWriter) Close() error {
+	var err error
+	for _, w := range f.writers {
+		if cerr := closeWriter(w); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFailover(t *testing.T) {
+	first, second := &flakyWriter{}, &flakyWriter{}
+	var third bytes.Buffer
+	f := Failover(first, second, &third)
+
+	f.Write([]byte("a\n"))
+	atomic.StoreInt32(&first.down, 1)
+	f.Write([]byte("b\n"))
+	atomic.StoreInt32(&second.down, 1)
+	f.Write([]byte("c\n"))
+
+	if got := f.Served(); !reflect.DeepEqual(got, []uint64{1, 1, 1}) {
+		t.Errorf("Served() = %v", got)
+	}
+	if first.String() != "a\n" || second.String() != "b\n" || third.String() != "c\n" {
+		t.Errorf("outputs = %q %q %q", first.String(), second.String(), third.String())
+	}
+
+	if _, err := Failover(first, second).Write([]byte("d\n")); err == nil {
+		t.Error("expected error when all writers fail")
+	}
+}
@@ -0,0 +1,112 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* 主输出目标出错时改写备用输出目标的输出目标，如网络输出目标出错时写本地文件 */
+/* 切换后每隔interval探测主输出目标，探测成功后切回 */
+type FallbackWriter struct {
+	mu       sync.Mutex
+	primary  io.Writer
+	fallback io.Writer
+	probe    func() error
+	failed   int32 /* 是否已切换到备用输出目标，原子读写 */
+	stop     chan struct{}
+	once     sync.Once
+}
+
+/* probe为nil时探测即视为成功，即每隔interval重新尝试主输出目标 */
+func NewFallbackWriter(primary, fallback io.Writer, probe func() error, interval time.Duration) *FallbackWriter {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	f := &FallbackWriter{primary: primary, fallback: fallback, probe: probe, stop: make(chan struct{})}
+	go f.probeLoop(interval)
+	return f
+}
+
+func (f *FallbackWriter) probeLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if atomic.LoadInt32(&f.failed) == 1 && (f.probe == nil || f.probe() == nil) {
+				atomic.StoreInt32(&f.failed, 0)
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+/* 是否正在使用备用输出目标 */
+func (f *FallbackWriter) Failed() bool {
+	return atomic.LoadInt32(&f.failed) == 1
+}
+
+func (f *FallbackWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if atomic.LoadInt32(&f.failed) == 0 {
+		n, err := f.primary.Write(p)
+		if err == nil {
+			return n, nil
+		}
+		atomic.StoreInt32(&f.failed, 1)
+	}
+	return f.fallback.Write(p)
+}
+
+/* 写出两个输出目标缓冲的内容 */
+func (f *FallbackWriter) Flush() error {
+	return f.each(flushWriter)
+}
+
+/* 落盘两个输出目标 */
+func (f *FallbackWriter) Sync() error {
+	return f.each(syncWriter)
+}
+
+/* 停止探测并关闭两个输出目标，标准输出及标准错误不会被关闭 */
+func (f *FallbackWriter) Close() error {
+	f.once.Do(func() { close(f.stop) })
+	return f.each(closeWriter)
+}
+
+func (f *FallbackWriter) each(fn func(io.Writer) error) error {
+	err := fn(f.primary)
+	if ferr := fn(f.fallback); err == nil {
+		err = ferr
+	}
+	return err
+}
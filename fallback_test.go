@@ -0,0 +1,91 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/* down为1时写入失败的输出目标 */
+type flakyWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	down int32
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.down) == 1 {
+		return 0, errors.New("connection refused")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *flakyWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestFallbackWriter(t *testing.T) {
+	primary := &flakyWriter{}
+	var local bytes.Buffer
+	probe := func() error {
+		if atomic.LoadInt32(&primary.down) == 1 {
+			return errors.New("down")
+		}
+		return nil
+	}
+	f := NewFallbackWriter(primary, &local, probe, 5*time.Millisecond)
+	defer f.Close()
+
+	f.Write([]byte("a\n"))
+	atomic.StoreInt32(&primary.down, 1)
+	f.Write([]byte("b\n"))
+	f.Write([]byte("c\n"))
+	if !f.Failed() || local.String() != "b\nc\n" {
+		t.Fatalf("failed = %v, local = %q", f.Failed(), local.String())
+	}
+
+	atomic.StoreInt32(&primary.down, 0)
+	deadline := time.Now().Add(time.Second)
+	for f.Failed() {
+		if time.Now().After(deadline) {
+			t.Fatal("did not switch back to primary")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	f.Write([]byte("d\n"))
+	if primary.String() != "a\nd\n" {
+		t.Errorf("primary = %q", primary.String())
+	}
+}
@@ -0,0 +1,283 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	globalMu     sync.Mutex   /* 保护以下字段的修改 */
+	userFields   []Field      /* SetGlobalFields设置的字段 */
+	appFields    []Field      /* SetAppInfo设置的字段 */
+	globalFields atomic.Value /* 附加到所有日志的字段，即appFields及userFields，[]Field */
+)
+
+/* 结构化字段 */
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+/* 构造结构化字段 */
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+/* 以error为键记录错误，输出时展开其errors.Unwrap错误链 */
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+/* 设置附加到所有Logger每条日志的字段，如主机名、进程号、应用名，便于集中查询时区分节点 */
+/* 用法：zlog.SetGlobalFields(append(zlog.HostFields(), zlog.F("app", "gateway"))...) */
+func SetGlobalFields(fields ...Field) {
+	globalMu.Lock()
+	userFields = append([]Field(nil), fields...)
+	storeGlobalFields()
+	globalMu.Unlock()
+}
+
+/* 设置附加到所有日志的应用信息，字段为service、version及env，为空的参数不附加 */
+/* 用于多个服务的日志集中查询时按服务筛选，与SetGlobalFields互不覆盖 */
+func SetAppInfo(name, version, env string) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	appFields = nil
+	for _, f := range []Field{F("service", name), F("version", version), F("env", env)} {
+		if f.Value != "" {
+			appFields = append(appFields, f)
+		}
+	}
+	storeGlobalFields()
+}
+
+/* 调用者需持有globalMu */
+func storeGlobalFields() {
+	globalFields.Store(append(append([]Field(nil), appFields...), userFields...))
+}
+
+/* 返回当前的全局字段 */
+func GlobalFields() []Field {
+	fields, _ := globalFields.Load().([]Field)
+	return fields
+}
+
+/* 返回当前主机名及进程号字段 */
+func HostFields() []Field {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return []Field{F("host", host), F("pid", os.Getpid())}
+}
+
+/* 返回可执行文件的构建信息字段，即模块版本build、VCS修订revision及是否有未提交修改dirty，无法读取的项不返回 */
+/* 用法：zlog.SetGlobalFields(append(zlog.HostFields(), zlog.BuildFields()...)...)，使每个日志文件都能对应到具体的构建 */
+func BuildFields() []Field {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	var fields []Field
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		fields = append(fields, F("build", v))
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			fields = append(fields, F("revision", s.Value))
+		case "vcs.modified":
+			fields = append(fields, F("dirty", s.Value == "true"))
+		}
+	}
+	return fields
+}
+
+/* 错误链中的一个错误 */
+type ErrorInfo struct {
+	Message string /* 该层错误的Error() */
+	Type    string /* 错误类型，如*fs.PathError */
+	Stack   string /* github.com/pkg/errors创建的错误附带的调用栈，没有时为空 */
+}
+
+/* 沿errors.Unwrap展开错误链，依次为最外层至最内层的错误，遇到nil指针等错误时到此为止 */
+func ErrorChain(err error) []ErrorInfo {
+	var chain []ErrorInfo
+	for ; err != nil; err = errors.Unwrap(err) {
+		if isNilValue(err) {
+			chain = append(chain, ErrorInfo{Message: "<nil>", Type: reflect.TypeOf(err).String()})
+			break
+		}
+
+		chain = append(chain, ErrorInfo{
+			Message: fmt.Sprint(err),
+			Type:    reflect.TypeOf(err).String(),
+			Stack:   stackOf(err),
+		})
+	}
+	return chain
+}
+
+/* 判断接口中的值是否为nil指针等，调用其方法可能panic */
+func isNilValue(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+/* 取出pkg/errors的StackTrace()，通过反射调用以免引入依赖 */
+func stackOf(err error) string {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+
+	return strings.TrimPrefix(fmt.Sprintf("%+v", m.Call(nil)[0].Interface()), "\n")
+}
+
+/* 字段值的JSON形式，error等无法直接编码的值转为字符串 */
+/* 通过fmt调用Error()及String()，nil指针输出<nil>，方法panic时输出%!v(PANIC=...)而不影响记录日志 */
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val
+	case error, fmt.Stringer:
+		return fmt.Sprint(val)
+	default:
+		return val
+	}
+}
+
+/* 字段的JSON对象形式 */
+func fieldsMap(fields []Field) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		m[field.Key] = jsonValue(field.Value)
+	}
+	return m
+}
+
+/* 字段值的文本形式，包含空格等字符时加引号 */
+func fieldText(v interface{}) string {
+	var s string
+	if str, ok := v.(string); ok {
+		s = str
+	} else {
+		s = fmt.Sprint(v) /* 同jsonValue，nil指针的error及fmt.Stringer不会panic */
+	}
+
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+/* 以key=value形式写入字段，错误附带的调用栈缩进后写在其后 */
+func writeFields(buf *bytes.Buffer, fields []Field) {
+	for _, field := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(field.Key)
+		buf.WriteByte('=')
+		buf.WriteString(fieldText(field.Value))
+	}
+	writeStacks(buf, fields)
+}
+
+/* 以制表符缩进写入字段中错误附带的调用栈 */
+func writeStacks(buf *bytes.Buffer, fields []Field) {
+	for _, field := range fields {
+		err, ok := field.Value.(error)
+		if !ok {
+			continue
+		}
+
+		for _, info := range ErrorChain(err) {
+			if info.Stack == "" {
+				continue
+			}
+			for _, line := range strings.Split(info.Stack, "\n") {
+				buf.WriteString("\n\t")
+				buf.WriteString(line)
+			}
+		}
+	}
+}
+
+/* 错误链中每层错误自身的内容，去掉fmt.Errorf以": "连接在其后的内层错误内容 */
+func chainCauses(chain []ErrorInfo) []string {
+	causes := make([]string, len(chain))
+	for i, info := range chain {
+		causes[i] = info.Message
+		if i+1 < len(chain) {
+			if own := strings.TrimSuffix(info.Message, ": "+chain[i+1].Message); own != info.Message && own != "" {
+				causes[i] = own
+			}
+		}
+	}
+	return causes
+}
+
+/* 与writeFields相同，但错误字段只写出最外层错误自身的内容，内层错误逐行以caused by:缩进写在其后 */
+func writeChainFields(buf *bytes.Buffer, fields []Field) {
+	var causes []string
+	for _, field := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(field.Key)
+		buf.WriteByte('=')
+
+		err, ok := field.Value.(error)
+		if !ok {
+			buf.WriteString(fieldText(field.Value))
+			continue
+		}
+
+		chain := chainCauses(ErrorChain(err))
+		buf.WriteString(fieldText(chain[0]))
+		causes = append(causes, chain[1:]...)
+	}
+
+	for _, cause := range causes {
+		buf.WriteString("\n\tcaused by: ")
+		buf.WriteString(cause)
+	}
+	writeStacks(buf, fields)
+}
@@ -0,0 +1,203 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type stack []string
+
+func (s stack) Format(f fmt.State, verb rune) {
+	for _, frame := range s {
+		fmt.Fprintf(f, "\n%s", frame)
+	}
+}
+
+type stackError struct {
+	msg string
+}
+
+func (e *stackError) Error() string     { return e.msg }
+func (e *stackError) StackTrace() stack { return stack{"main.load", "\tmain.go:12"} }
+
+func TestErrorChain(t *testing.T) {
+	inner := &stackError{"no such file"}
+	err := fmt.Errorf("loading config: %w", inner)
+
+	chain := ErrorChain(err)
+	if len(chain) != 2 || chain[1].Message != "no such file" || chain[1].Type != "*zlog.stackError" {
+		t.Fatalf("unexpected chain: %+v", chain)
+	}
+	if chain[0].Stack != "" || chain[1].Stack != "main.load\n\tmain.go:12" {
+		t.Fatalf("unexpected stacks: %+v", chain)
+	}
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.Errorw("startup failed", err, F("attempt", 3))
+	out := buf.String()
+	if !strings.Contains(out, `startup failed error="loading config: no such file" attempt=3`) {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if !strings.Contains(out, "\n\tmain.load\n\t\tmain.go:12") {
+		t.Fatalf("stack missing: %q", out)
+	}
+}
+
+func TestErrorChainText(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true, ErrorChain: true})
+
+	err := fmt.Errorf("saving user: %w", fmt.Errorf("open /data/u.db: %w", errors.New("permission denied")))
+	l.Errorw("request failed", err, F("id", 7))
+	l.Warningln("retrying", err)
+	l.Logw(WARNING, "degraded", Err(err))
+
+	lines := strings.Split(buf.String(), "\n")
+	want := []string{
+		"request failed error=\"saving user\" id=7",
+		"\tcaused by: open /data/u.db",
+		"\tcaused by: permission denied",
+	}
+	if len(lines) != 6 || !strings.HasSuffix(lines[0], want[0]) || lines[1] != want[1] || lines[2] != want[2] {
+		t.Fatalf("unexpected output: %q", lines)
+	}
+	if !strings.HasSuffix(lines[4], `degraded error="saving user: open /data/u.db: permission denied"`) {
+		t.Errorf("WARNING chain should stay on one line: %q", lines[4])
+	}
+}
+
+type nilStringer struct{ name string }
+
+func (s *nilStringer) String() string { return s.name }
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+func TestNilFieldValues(t *testing.T) {
+	var s *nilStringer
+	var e *nilError
+	for _, f := range []Formatter{&TextFormatter{NoColor: true, ErrorChain: true}, &JSONFormatter{}, &DatadogFormatter{}} {
+		var buf bytes.Buffer
+		l := NewLogger()
+		l.SetOutput(&buf)
+		l.SetFormatter(f)
+		l.Errorw("nil values", e, F("peer", s))
+		if out := strings.ReplaceAll(buf.String(), `\u003cnil\u003e`, "<nil>"); strings.Count(out, "<nil>") != 2 {
+			t.Errorf("%T: unexpected output %q", f, out)
+		}
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.RedactKeys("token")
+
+	l.Logw(INFO, "issued", F("Token", "abc"), F("note", "token=xyz"), Err(errors.New("none")))
+	out := buf.String()
+	if strings.Contains(out, "abc") || strings.Contains(out, "xyz") || !strings.Contains(out, "Token=***") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestRedactErrorFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.RedactKeys("password")
+	if err := l.RedactPatterns(`\b\d{16}\b`); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := []Field{Err(errors.New("card 4111111111111111 password=hunter2 declined")), F("password", "hunter2"), F("peer", &nilStringer{"card 4111111111111111"})}
+	l.Logw(ERROR, "payment failed", fs...)
+	out := buf.String()
+	if strings.Contains(out, "4111") || strings.Contains(out, "hunter2") || !strings.Contains(out, `error="card *** password=*** declined"`) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if fs[1].Value != "hunter2" {
+		t.Errorf("caller's fields modified: %v", fs)
+	}
+}
+
+func TestGlobalFields(t *testing.T) {
+	SetGlobalFields(append(HostFields(), F("app", "gateway"))...)
+	defer SetGlobalFields()
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.Logw(INFO, "started", F("port", 8080))
+
+	want := fmt.Sprintf("started port=8080 host=%s pid=%d app=gateway", GlobalFields()[0].Value, os.Getpid())
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("missing %q in %q", want, buf.String())
+	}
+}
+
+func TestAppInfo(t *testing.T) {
+	SetGlobalFields(F("region", "eu"))
+	SetAppInfo("gateway", "1.4.2", "")
+	defer SetAppInfo("", "", "")
+	defer SetGlobalFields()
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.Infoln("started")
+
+	if want := "started service=gateway version=1.4.2 region=eu\n"; !strings.HasSuffix(buf.String(), want) {
+		t.Fatalf("missing %q in %q", want, buf.String())
+	}
+}
+
+func TestBuildFields(t *testing.T) {
+	for _, f := range BuildFields() {
+		switch f.Key {
+		case "build", "revision":
+			if s, _ := f.Value.(string); s == "" {
+				t.Errorf("empty %s", f.Key)
+			}
+		case "dirty":
+			if _, ok := f.Value.(bool); !ok {
+				t.Errorf("dirty: %#v", f.Value)
+			}
+		default:
+			t.Errorf("unexpected field %q", f.Key)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* 文件大小单位 */
+const (
+	KB int64 = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+var errFileClosed = errors.New("zlog: file writer closed")
+
+const rotateBackoff = time.Minute /* 轮转失败后再次尝试前的等待时间 */
+
+/* 写入本地文件的输出目标，大小超过上限时轮转，path.1为最近一次轮转出的文件 */
+type FileWriter struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64 /* 超过该大小时轮转，为0时不轮转 */
+	backups int   /* 保留的轮转文件数，为0时轮转出的文件直接删除 */
+	f       *os.File
+	size    int64
+	closed  bool
+	retryAt time.Time /* 轮转失败后，此前不再尝试轮转 */
+	failing bool      /* 上次轮转失败，错误已报告过一次 */
+}
+
+/* 以追加方式打开path，目录不存在时创建 */
+func NewFileWriter(path string, maxSize int64, backups int) (*FileWriter, error) {
+	w := &FileWriter{path: path, maxSize: maxSize, backups: backups}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+/* 写入后超过大小上限时先轮转，单条日志不会被拆分到两个文件 */
+/* 轮转失败时仍写入原文件，只在首次失败时返回错误，等待rotateBackoff后再次尝试 */
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errFileClosed
+	}
+
+	var rerr error
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize && !time.Now().Before(w.retryAt) {
+		if err := w.rotate(); err != nil {
+			w.retryAt = time.Now().Add(rotateBackoff)
+			if !w.failing {
+				rerr = err
+			}
+			w.failing = true
+		} else {
+			w.failing = false
+		}
+	}
+
+	if w.f == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	if err == nil {
+		err = rerr
+	}
+	return n, err
+}
+
+/* 立即轮转，用于响应外部的轮转信号 */
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errFileClosed
+	}
+	return w.rotate()
+}
+
+/* 轮转失败且原文件无法重新打开时w.f为nil，下次写入时再打开 */
+func (w *FileWriter) rotate() error {
+	if w.f != nil {
+		w.f.Close()
+		w.f = nil
+	}
+
+	var err error
+	if w.backups > 0 {
+		for i := w.backups; i > 1; i-- {
+			os.Rename(w.backup(i-1), w.backup(i))
+		}
+		err = os.Rename(w.path, w.backup(1))
+	} else {
+		err = os.Remove(w.path)
+	}
+
+	/* 轮转失败时继续写入原文件 */
+	if oerr := w.open(); err == nil {
+		err = oerr
+	}
+	return err
+}
+
+func (w *FileWriter) backup(n int) string {
+	return w.path + "." + strconv.Itoa(n)
+}
+
+func (w *FileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+	return w.f.Sync()
+}
+
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
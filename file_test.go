@@ -0,0 +1,111 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	w, err := NewFileWriter(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]string{path: "fourth\n", path + ".1": "third\n", path + ".2": "second\n"} {
+		b, err := os.ReadFile(name)
+		if err != nil || string(b) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(name), b, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, stat .3: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("write after close: %v", err)
+	}
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewFileWriter(path, 12, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("more\n"))
+	if b, _ := os.ReadFile(path); string(b) != "more\n" {
+		t.Errorf("content = %q, want rotation to account for the existing size", b)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("backups=0 should not keep rotated files: %v", err)
+	}
+}
+
+func TestFileWriterRotateFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	/* 轮转目标是非空目录，重命名会失败 */
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewFileWriter(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	if n, err := w.Write([]byte("second\n")); n != 7 || err == nil {
+		t.Errorf("first failed rotation: n=%d err=%v, want the entry written and the error reported", n, err)
+	}
+	if _, err := w.Write([]byte("third\n")); err != nil {
+		t.Errorf("rotation error should be reported once and retried after a backoff: %v", err)
+	}
+
+	if b, _ := os.ReadFile(path); string(b) != "first\nsecond\nthird\n" {
+		t.Errorf("content = %q, want all entries kept in the original file", b)
+	}
+}
@@ -0,0 +1,186 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"regexp"
+)
+
+/* 按正则匹配日志内容的过滤规则 */
+type messageFilter struct {
+	drop []*regexp.Regexp /* 匹配任一规则的日志将被丢弃 */
+	keep []*regexp.Regexp /* 非空时，只保留匹配任一规则的日志 */
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func matchAny(res []*regexp.Regexp, msg string) bool {
+	for _, re := range res {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *messageFilter) allow(msg string) bool {
+	if matchAny(f.drop, msg) {
+		return false
+	}
+	return len(f.keep) == 0 || matchAny(f.keep, msg)
+}
+
+/* 丢弃内容匹配任一正则的日志，可在运行时多次调用追加规则 */
+func (l *Logger) DropMatching(exprs ...string) error {
+	res, err := compileAll(exprs)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.filter.drop = append(l.filter.drop, res...)
+	l.mu.Unlock()
+	return nil
+}
+
+/* 只保留内容匹配任一正则的日志，可在运行时多次调用追加规则 */
+func (l *Logger) KeepMatching(exprs ...string) error {
+	res, err := compileAll(exprs)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.filter.keep = append(l.filter.keep, res...)
+	l.mu.Unlock()
+	return nil
+}
+
+/* 自定义的过滤规则，如只在工作时间输出、只输出指定租户的日志 */
+type Filter interface {
+	Allow(e *Entry) bool
+}
+
+/* 将普通函数用作Filter */
+type FilterFunc func(e *Entry) bool
+
+func (f FilterFunc) Allow(e *Entry) bool {
+	return f(e)
+}
+
+/* 添加自定义过滤规则，日志需通过所有规则才会输出，在级别过滤之后执行 */
+func (l *Logger) AddFilter(filters ...Filter) {
+	l.mu.Lock()
+	l.filters = append(l.filters, filters...)
+	l.mu.Unlock()
+}
+
+/* 清除所有内容过滤规则及自定义过滤规则 */
+func (l *Logger) ClearFilters() {
+	l.mu.Lock()
+	l.filter = messageFilter{}
+	l.filters = nil
+	l.mu.Unlock()
+}
+
+func (l *Logger) allow(e *Entry) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if !l.filter.allow(e.Message) {
+		return false
+	}
+	for _, f := range l.filters {
+		if !f.Allow(e) {
+			return false
+		}
+	}
+	return true
+}
+
+/* 自行决定是否接收日志的输出目标 */
+type entryFilter interface {
+	allowEntry(e *Entry) bool
+}
+
+type filteredWriter struct {
+	w io.Writer
+	f Filter
+}
+
+/* 自行格式化整条日志的输出目标的过滤包装 */
+type filteredEntryWriter struct {
+	filteredWriter
+}
+
+/* 返回只接收通过f的日志的输出目标，用于单个输出目标的过滤，如 zlog.Route(INFO, SILENCE, zlog.WithFilter(alerts, tenants)) */
+func WithFilter(w io.Writer, f Filter) io.Writer {
+	if _, ok := w.(entryWriter); ok {
+		return &filteredEntryWriter{filteredWriter{w: w, f: f}}
+	}
+	return &filteredWriter{w: w, f: f}
+}
+
+func (fw *filteredWriter) allowEntry(e *Entry) bool {
+	return fw.f.Allow(e)
+}
+
+func (fw *filteredWriter) Write(p []byte) (int, error) {
+	return fw.w.Write(p)
+}
+
+func (fw *filteredEntryWriter) WriteEntry(e *Entry) error {
+	return fw.w.(entryWriter).WriteEntry(e)
+}
+
+func (fw *filteredWriter) Flush() error {
+	return flushWriter(fw.w)
+}
+
+func (fw *filteredWriter) Sync() error {
+	return syncWriter(fw.w)
+}
+
+func (fw *filteredWriter) QueueDepth() int {
+	return queueDepth(fw.w)
+}
+
+func (fw *filteredWriter) Dropped() uint64 {
+	return droppedCount(fw.w)
+}
+
+/* 标准输出及标准错误不会被关闭 */
+func (fw *filteredWriter) Close() error {
+	return closeWriter(fw.w)
+}
@@ -0,0 +1,67 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	var buf, alerts bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.Route(ERROR, SILENCE, &buf)
+	l.Route(ERROR, SILENCE, WithFilter(&alerts, FilterFunc(func(e *Entry) bool {
+		for _, f := range e.Fields {
+			if f.Key == "tenant" && f.Value == "acme" {
+				return true
+			}
+		}
+		return false
+	})))
+	l.AddFilter(FilterFunc(func(e *Entry) bool {
+		return e.Tag != "noisy"
+	}))
+
+	l.Tagged("noisy").Errorln("suppressed")
+	l.Logw(ERROR, "acme down", F("tenant", "acme"))
+	l.Logw(ERROR, "other down", F("tenant", "other"))
+
+	if out := buf.String(); strings.Contains(out, "suppressed") || !strings.Contains(out, "acme down") || !strings.Contains(out, "other down") {
+		t.Errorf("global: %q", out)
+	}
+	if out := alerts.String(); !strings.Contains(out, "acme down") || strings.Contains(out, "other down") {
+		t.Errorf("sink: %q", out)
+	}
+
+	buf.Reset()
+	l.ClearFilters()
+	l.Tagged("noisy").Errorln("back")
+	if !strings.Contains(buf.String(), "back") {
+		t.Errorf("after clear: %q", buf.String())
+	}
+}
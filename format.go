@@ -0,0 +1,275 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxPooledBuffer = 64 << 10 /* 超过该大小的缓冲不再放回池中，避免长期占用内存 */
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+/* 一条待输出的日志 */
+type Entry struct {
+	Level     uint8     /* 日志级别 */
+	Time      time.Time /* 记录时间 */
+	Tag       string    /* 标志，即调用者的包路径 */
+	Func      string    /* 调用者函数名 */
+	File      string    /* 调用者源文件完整路径，未知时为空 */
+	Line      int       /* 调用者行号 */
+	Message   string    /* 格式化后的日志内容，不含结尾换行 */
+	Fields    []Field   /* 结构化字段 */
+	Goroutine uint64    /* 记录日志的goroutine ID，仅在格式需要时填充，否则为0 */
+}
+
+func newEntry(level uint8, tag, fn, msg string, fields ...Field) *Entry {
+	return &Entry{Level: level, Time: time.Now(), Tag: tag, Func: fn, Message: msg, Fields: fields}
+}
+
+/* 日志格式，将日志写入缓冲，结尾换行可省略 */
+type Formatter interface {
+	Format(buf *bytes.Buffer, e *Entry)
+}
+
+/* 默认的文本格式：时间 [级别][标志: 函数] 内容 */
+type TextFormatter struct {
+	LevelNames map[uint8]string /* 覆盖级别的显示名称，如{DEBUG: "DBG"}，未指定的级别使用LogLevelNames */
+	Goroutine  bool             /* 输出goroutine ID，如[g42]，便于区分并发交错的日志 */
+	NoColor    bool             /* 不输出控制台颜色，用于文件及网页 */
+	Symbols    bool             /* 在级别名称前输出符号(ℹ ⚠ ✖等)，便于本地开发时快速浏览 */
+	SymbolOnly bool             /* 只输出符号而不输出级别名称，如[⚠] */
+	Multiline  MultilineMode    /* 内容包含换行时的处理方式 */
+	FullTag    bool             /* 输出完整的标志而非最后一级，通常与SetTrimPrefixes配合 */
+	Caller     CallerFormat     /* 调用者部分的形式 */
+	ErrorChain bool             /* ERROR及以上级别的日志中，错误字段只输出最外层的内容，内层错误逐行以caused by:缩进输出 */
+}
+
+/* 调用者部分的形式 */
+type CallerFormat uint8
+
+const (
+	CallerTagFunc CallerFormat = iota /* 标志: 函数，如[p2p: Dial] */
+	CallerTag                         /* 只输出标志，如[p2p] */
+	CallerDotFunc                     /* 标志.函数，如[p2p.Dial] */
+	CallerFile                        /* 标志/源文件:行号，如[p2p/dial.go:42]，源文件未知时同CallerTag */
+	CallerFull                        /* 完整标志.函数，如[github/com/fpay/fpay/p2p.Dial]，忽略FullTag */
+)
+
+func (f *TextFormatter) writeCaller(buf *bytes.Buffer, e *Entry) {
+	if f.Caller == CallerFull {
+		buf.WriteString(e.Tag)
+		buf.WriteByte('.')
+		buf.WriteString(e.Func)
+		return
+	}
+
+	if f.FullTag {
+		buf.WriteString(e.Tag)
+	} else {
+		buf.WriteString(lastPath(e.Tag))
+	}
+
+	switch f.Caller {
+	case CallerTagFunc:
+		buf.WriteString(": ")
+		buf.WriteString(e.Func)
+	case CallerDotFunc:
+		buf.WriteByte('.')
+		buf.WriteString(e.Func)
+	case CallerFile:
+		if e.File != "" {
+			buf.WriteByte('/')
+			buf.WriteString(filepath.Base(e.File))
+			buf.WriteByte(':')
+			buf.WriteString(strconv.Itoa(e.Line))
+		}
+	}
+}
+
+/* 日志内容包含换行(如调用栈、SQL)时的处理方式 */
+type MultilineMode uint8
+
+const (
+	MultilineRaw    MultilineMode = iota /* 原样输出 */
+	MultilineIndent                      /* 后续行以制表符缩进，便于区分日志的开头 */
+	MultilineEscape                      /* 将换行转义为\n，保证每条日志只占一行，包括错误的调用栈 */
+)
+
+var (
+	indentReplacer = strings.NewReplacer("\r\n", "\n\t", "\n", "\n\t")
+	escapeReplacer = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+)
+
+/* 级别对应的符号，自定义级别按所在区间对应 */
+func levelSymbol(level uint8) string {
+	switch {
+	case level < INFO:
+		return "·"
+	case level == INFO:
+		return "ℹ"
+	case level < WARNING:
+		return "✔"
+	case level < ERROR:
+		return "⚠"
+	default:
+		return "✖"
+	}
+}
+
+/* 需要Entry.Goroutine的格式 */
+type goroutineFormatter interface {
+	wantsGoroutine() bool
+}
+
+/* 自行分隔每条记录的二进制格式，输出时不追加结尾换行 */
+type binaryFormatter interface {
+	binary()
+}
+
+func (f *TextFormatter) wantsGoroutine() bool {
+	return f.Goroutine
+}
+
+func (f *TextFormatter) levelName(level uint8) string {
+	name, ok := f.LevelNames[level]
+	if !ok {
+		name = LogLevelNames[level]
+	}
+
+	switch {
+	case f.SymbolOnly:
+		return levelSymbol(level)
+	case f.Symbols:
+		return levelSymbol(level) + " " + name
+	default:
+		return name
+	}
+}
+
+func (f *TextFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	var scratch [32]byte
+	buf.Write(e.Time.AppendFormat(scratch[:0], "2006/01/02 15:04:05 "))
+
+	buf.WriteByte('[')
+	if color := levelColors[e.Level]; color != "" && !f.NoColor {
+		buf.WriteString("\x1b[")
+		buf.WriteString(color)
+		buf.WriteByte('m')
+		buf.WriteString(f.levelName(e.Level))
+		buf.WriteString("\x1b[0m")
+	} else {
+		buf.WriteString(f.levelName(e.Level))
+	}
+
+	if e.Goroutine != 0 {
+		buf.WriteString("][g")
+		buf.WriteString(strconv.FormatUint(e.Goroutine, 10))
+	}
+
+	buf.WriteString("][")
+	f.writeCaller(buf, e)
+	buf.WriteString("] ")
+	start := buf.Len()
+	if f.Multiline == MultilineIndent {
+		indentReplacer.WriteString(buf, e.Message)
+	} else {
+		buf.WriteString(e.Message)
+	}
+	if f.ErrorChain && e.Level >= ERROR {
+		writeChainFields(buf, e.Fields)
+	} else {
+		writeFields(buf, e.Fields)
+	}
+
+	if f.Multiline == MultilineEscape {
+		if tail := buf.Bytes()[start:]; bytes.ContainsAny(tail, "\r\n") {
+			escaped := escapeReplacer.Replace(string(tail))
+			buf.Truncate(start)
+			buf.WriteString(escaped)
+		}
+	}
+}
+
+/* 一条日志的JSON形式，JSONFormatter、Splunk及调试接口共用 */
+type entryJSON struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Tag     string                 `json:"tag"`
+	Func    string                 `json:"func"`
+	File    string                 `json:"file,omitempty"`
+	Line    int                    `json:"line,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+func newEntryJSON(e *Entry) entryJSON {
+	return entryJSON{
+		Time:    e.Time,
+		Level:   LogLevelNames[e.Level],
+		Tag:     e.Tag,
+		Func:    e.Func,
+		File:    e.File,
+		Line:    e.Line,
+		Message: e.Message,
+		Fields:  fieldsMap(e.Fields),
+	}
+}
+
+/* 每行一个JSON对象的格式，键为time、level、tag、func、file、line、message及fields */
+type JSONFormatter struct{}
+
+/* JSON格式，供WithFormat(JSON)及SetFormatter(JSON)使用 */
+var JSON Formatter = &JSONFormatter{}
+
+func (f *JSONFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	if err := json.NewEncoder(buf).Encode(newEntryJSON(e)); err != nil {
+		b, _ := json.Marshal("zlog: " + err.Error())
+		buf.WriteString(`{"level":"ERROR","message":`)
+		buf.Write(b)
+		buf.WriteString("}\n")
+	}
+}
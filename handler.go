@@ -0,0 +1,101 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+/* 日志处理流程中的一个环节，接收通过级别、内容过滤及采样的日志 */
+/* Entry在Handle返回后可能被复用，需要异步处理时应复制 */
+type Handler interface {
+	Handle(e *Entry)
+}
+
+/* 将普通函数用作Handler */
+type HandlerFunc func(e *Entry)
+
+func (f HandlerFunc) Handle(e *Entry) {
+	f(e)
+}
+
+/* 处理流程的中间环节，可修改、补充、丢弃(不调用next)或复制日志后交给next */
+type Middleware func(next Handler) Handler
+
+/* 以完整的处理流程(全局字段、脱敏、级别及内容过滤、采样)处理外部构造的日志，如其他日志库的桥接 */
+/* Logger因此也是Handler，可作为另一Logger流程的下一环节 */
+func (l *Logger) Handle(e *Entry) {
+	l.emit(e, false)
+}
+
+/* 替换处理流程的最后一环，默认为按格式写入输出目标，h为nil时恢复默认 */
+func (l *Logger) SetHandler(h Handler) {
+	l.mu.Lock()
+	l.handler = h
+	l.buildPipeline()
+	l.mu.Unlock()
+}
+
+/* 追加中间环节，先追加的先执行，中间环节只处理通过级别、内容过滤及采样的日志 */
+func (l *Logger) Use(mws ...Middleware) {
+	l.mu.Lock()
+	l.middlewares = append(l.middlewares, mws...)
+	l.buildPipeline()
+	l.mu.Unlock()
+}
+
+/* 清除所有中间环节 */
+func (l *Logger) ClearMiddleware() {
+	l.mu.Lock()
+	l.middlewares = nil
+	l.buildPipeline()
+	l.mu.Unlock()
+}
+
+/* 由中间环节及最后一环组装处理流程，调用者需持有写锁 */
+func (l *Logger) buildPipeline() {
+	if l.handler == nil && len(l.middlewares) == 0 {
+		l.pipeline = nil
+		return
+	}
+
+	h := l.handler
+	if h == nil {
+		h = HandlerFunc(l.write)
+	}
+	for i := len(l.middlewares) - 1; i >= 0; i-- {
+		h = l.middlewares[i](h)
+	}
+	l.pipeline = h
+}
+
+/* 返回默认的最后一环，即按格式写入输出目标，便于在自定义Handler中调用 */
+func (l *Logger) WriteHandler() Handler {
+	return HandlerFunc(l.write)
+}
+
+/* 替换默认日志记录器处理流程的最后一环 */
+func SetHandler(h Handler) {
+	std.SetHandler(h)
+}
+
+/* 为默认日志记录器追加中间环节 */
+func Use(mws ...Middleware) {
+	std.Use(mws...)
+}
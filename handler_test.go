@@ -0,0 +1,128 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(INFO)
+
+	var got []*Entry
+	l.SetHandler(HandlerFunc(func(e *Entry) {
+		got = append(got, e)
+		l.WriteHandler().Handle(e)
+	}))
+
+	l.Debugln("hidden")
+	l.Logw(INFO, "shown", F("k", 1))
+	if len(got) != 1 || got[0].Message != "shown" || got[0].Fields[0].Key != "k" || got[0].Line == 0 {
+		t.Fatalf("entries: %+v", got)
+	}
+	if !strings.Contains(buf.String(), "] shown k=1") {
+		t.Errorf("output: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.SetHandler(nil)
+	l.Handle(newEntry(WARNING, "bridge", "Log", "external"))
+	l.Handle(newEntry(DEBUG, "bridge", "Log", "filtered"))
+	if out := buf.String(); !strings.Contains(out, "[bridge: Log] external") || strings.Contains(out, "filtered") {
+		t.Errorf("external: %q", out)
+	}
+}
+
+func TestHandlerForward(t *testing.T) {
+	var buf bytes.Buffer
+	central := NewLogger()
+	central.SetOutput(&buf)
+	central.SetFormatter(&JSONFormatter{})
+
+	l := NewLogger()
+	l.SetHandler(central)
+	l.Infoln("forwarded")
+	if !strings.Contains(buf.String(), `"message":"forwarded"`) {
+		t.Errorf("forwarded: %q", buf.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+
+	var order []string
+	l.Use(func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			order = append(order, "enrich")
+			e.Fields = append(e.Fields, F("region", "eu"))
+			next.Handle(e)
+		})
+	}, func(next Handler) Handler {
+		return HandlerFunc(func(e *Entry) {
+			order = append(order, "drop")
+			if strings.HasPrefix(e.Message, "noise") {
+				return
+			}
+			next.Handle(e)
+			if e.Level >= ERROR {
+				dup := *e
+				dup.Message = "copy: " + e.Message
+				next.Handle(&dup)
+			}
+		})
+	})
+
+	l.Infoln("noise")
+	l.Infoln("hello")
+	l.Errorln("boom")
+
+	out := buf.String()
+	for _, want := range []string{"] hello region=eu\n", "] boom region=eu\n", "] copy: boom region=eu\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "noise") {
+		t.Errorf("dropped entry written: %q", out)
+	}
+	if len(order) != 6 || order[0] != "enrich" || order[1] != "drop" {
+		t.Errorf("order: %v", order)
+	}
+
+	buf.Reset()
+	l.ClearMiddleware()
+	l.Infoln("noise")
+	if !strings.Contains(buf.String(), "] noise\n") {
+		t.Errorf("after clear: %q", buf.String())
+	}
+}
@@ -0,0 +1,63 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	helpers     sync.Map /* Helper标记的函数全名 -> struct{} */
+	helperPCs   sync.Map /* 已调用过Helper的PC，避免重复解析，uintptr -> struct{} */
+	helperCount int32    /* 已标记的函数数量，为0时解析调用者无需查找多层 */
+)
+
+/* 将调用Helper的函数标记为日志的封装函数，解析调用者时自动跳过，类似testing.T.Helper */
+/* 用法：func logRequest(r *http.Request) { zlog.Helper(); zlog.Infof("%s %s", r.Method, r.URL) } */
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	if _, ok := helperPCs.Load(pcs[0]); ok {
+		return
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, loaded := helpers.LoadOrStore(frame.Function, struct{}{}); !loaded {
+		atomic.AddInt32(&helperCount, 1)
+		clearCallerCache()
+	}
+	helperPCs.Store(pcs[0], struct{}{})
+}
+
+func isHelper(function string) bool {
+	if atomic.LoadInt32(&helperCount) == 0 {
+		return false
+	}
+
+	_, ok := helpers.Load(function)
+	return ok
+}
@@ -0,0 +1,276 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const AccessTag = "http" /* 访问日志使用的标志，可通过SetTagLevel单独设置级别 */
+
+/* 记录状态码及写出字节数的http.ResponseWriter */
+type accessWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *accessWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *accessWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+/* 供http.ResponseController访问原始ResponseWriter */
+func (w *accessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+/* 记录每个请求的方法、路径、状态码、耗时、字节数、来源地址及协议的HTTP中间件 */
+/* 查询串、Referer、User-Agent及Basic认证用户名非空时附加，请求带traceparent时附加trace_id及parent_id */
+/* 正常请求为INFO，耗时超过slow(为0时不判断)为WARNING，5xx为ERROR */
+func (l *Logger) AccessLog(next http.Handler, slow time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		aw := &accessWriter{ResponseWriter: w}
+		next.ServeHTTP(aw, r)
+		latency := time.Since(start)
+
+		if aw.status == 0 {
+			aw.status = http.StatusOK
+		}
+
+		level := INFO
+		switch {
+		case aw.status >= 500:
+			level = ERROR
+		case slow > 0 && latency >= slow:
+			level = WARNING
+		}
+
+		if !l.Enabled(level, AccessTag) {
+			return
+		}
+
+		fields := []Field{
+			F("status", aw.status),
+			F("latency", latency),
+			F("bytes", aw.bytes),
+			F("remote", r.RemoteAddr),
+			F("proto", r.Proto),
+		}
+		for _, f := range []Field{F("query", r.URL.RawQuery), F("referer", r.Referer()), F("user_agent", r.UserAgent())} {
+			if f.Value != "" {
+				fields = append(fields, f)
+			}
+		}
+		if user, _, ok := r.BasicAuth(); ok {
+			fields = append(fields, F("user", user))
+		}
+		if tc, ok := TraceFromRequest(r); ok {
+			fields = append(fields, tc.Fields()...)
+		}
+
+		l.LogEntry(&Entry{
+			Level:   level,
+			Tag:     AccessTag,
+			Func:    "AccessLog",
+			Message: r.Method + " " + r.URL.Path,
+			Fields:  fields,
+		})
+	})
+}
+
+/* 使用默认日志记录器的访问日志中间件 */
+func AccessLog(next http.Handler, slow time.Duration) http.Handler {
+	return std.AccessLog(next, slow)
+}
+
+/* 请求头header非空时在请求上下文中携带level，配合Ctx(r.Context())单独调试该请求 */
+/* 如 zlog.LevelHeader(mux, "X-Debug", zlog.VERBOSE) */
+func LevelHeader(next http.Handler, header string, level uint8) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(header) != "" {
+			r = r.WithContext(ContextWithLevel(r.Context(), level))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+/* 最近日志的查询条件 */
+type recentQuery struct {
+	level uint8  /* 最低级别 */
+	tag   string /* 标志前缀，为空时不限 */
+	limit int    /* 最多返回的条数，为0时不限 */
+}
+
+func parseRecentQuery(r *http.Request) (recentQuery, error) {
+	var q recentQuery
+	values := r.URL.Query()
+	if name := values.Get("level"); name != "" {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return q, err
+		}
+		q.level = level
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return q, errors.New("zlog: invalid limit " + strconv.Quote(limit))
+		}
+		q.limit = n
+	}
+
+	q.tag = values.Get("tag")
+	return q, nil
+}
+
+func (q recentQuery) match(e *Entry) bool {
+	return e.Level >= q.level && (q.tag == "" || e.Tag == q.tag || strings.HasPrefix(e.Tag, q.tag+"/"))
+}
+
+func (q recentQuery) filter(entries []Entry) []Entry {
+	res := entries[:0]
+	for i := range entries {
+		if q.match(&entries[i]) {
+			res = append(res, entries[i])
+		}
+	}
+
+	if q.limit > 0 && len(res) > q.limit {
+		res = res[len(res)-q.limit:]
+	}
+	return res
+}
+
+/* 输出最近日志的调试页面，需先通过SetRecentSize启用最近日志缓冲 */
+/* 支持查询参数level(最低级别)、tag(标志前缀)、limit(条数)及format=json */
+func RecentHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseRecentQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entries := q.filter(Recent())
+		if r.URL.Query().Get("format") == "json" {
+			res := make([]entryJSON, len(entries))
+			for i := range entries {
+				res[i] = newEntryJSON(&entries[i])
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		f := &TextFormatter{NoColor: true}
+		buf := getBuffer()
+		defer putBuffer(buf)
+		for i := range entries {
+			f.Format(buf, &entries[i])
+			buf.WriteByte('\n')
+		}
+		w.Write(buf.Bytes())
+	})
+}
+
+/* 以Server-Sent Events实时推送日志，相当于在浏览器中tail -f */
+/* 查询参数与RecentHandler相同(limit除外)，每个连接可使用不同的级别及标志过滤 */
+/* 存在连接时未达到输出级别的日志也会被格式化，仅应在调试时开启 */
+func StreamHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseRecentQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		entries, cancel := subscribe(256)
+		defer cancel()
+
+		asJSON := r.URL.Query().Get("format") == "json"
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		f := &TextFormatter{NoColor: true}
+		buf := getBuffer()
+		defer putBuffer(buf)
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-entries:
+				if !q.match(&e) {
+					continue
+				}
+
+				buf.Reset()
+				if asJSON {
+					json.NewEncoder(buf).Encode(newEntryJSON(&e))
+				} else {
+					f.Format(buf, &e)
+				}
+
+				/* SSE中每行内容都需要data:前缀 */
+				for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+					w.Write([]byte("data: " + line + "\n"))
+				}
+				w.Write([]byte("\n"))
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	h := l.AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("hello"))
+	}), 0)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/fail", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "INFO") || !strings.Contains(lines[0], "GET /ok status=200") || !strings.Contains(lines[0], "bytes=5") {
+		t.Errorf("unexpected line: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "ERROR") || !strings.Contains(lines[1], "POST /fail status=500") {
+		t.Errorf("unexpected line: %q", lines[1])
+	}
+}
+
+func TestRecentHandler(t *testing.T) {
+	SetRecentSize(10)
+	defer SetRecentSize(0)
+
+	l := NewLogger()
+	l.SetOutput(&bytes.Buffer{})
+	l.Tagged("fpay/p2p").Debugln("dialing")
+	l.Tagged("fpay/p2p/gossip").Warningln("peer dropped")
+	l.Tagged("fpay/db").Errorln("slow query")
+
+	rec := httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?level=warning&tag=fpay/p2p", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "[WARNING][gossip: TestRecentHandler] peer dropped") || strings.Contains(body, "dialing") || strings.Contains(body, "slow query") {
+		t.Fatalf("unexpected text body: %q", body)
+	}
+
+	rec = httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?format=json&limit=1", nil))
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0]["message"] != "slow query" || entries[0]["level"] != "ERROR" {
+		t.Fatalf("unexpected json body: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs?level=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level accepted: %d", rec.Code)
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	srv := httptest.NewServer(StreamHandler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"?level=debug&tag=fpay", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	l := NewLogger()
+	l.SetOutput(&bytes.Buffer{})
+	l.SetLevel(ERROR)
+	go func() {
+		for ctx.Err() == nil {
+			l.Tagged("other").Warningln("ignored")
+			l.Tagged("fpay/p2p").Debugln("live entry")
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "ignored") {
+			t.Fatalf("filtered entry streamed: %q", line)
+		}
+		if strings.HasPrefix(line, "data: ") && strings.Contains(line, "[DEBUG][p2p: ") && strings.HasSuffix(line, "live entry") {
+			return
+		}
+	}
+	t.Fatal("stream ended without entry")
+}
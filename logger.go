@@ -0,0 +1,771 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* 日志记录器，持有独立的全局级别及标志级别配置 */
+type Logger struct {
+	mu            sync.RWMutex   /* 保护以下配置，写日志时只需读锁 */
+	wmu           sync.Mutex     /* 保证同一时刻只有一条日志写入输出目标 */
+	level         uint32         /* 全局日志级别，原子读写 */
+	tagLevels     atomic.Value   /* 指定标志日志级别，map[string]uint8，修改时整体替换 */
+	minLevel      uint32         /* 全局及所有标志级别中的最低者，原子读写，低于它的日志无需解析调用者 */
+	out           io.Writer      /* 日志输出目标，为nil时使用标准库log的输出目标 */
+	errOut        io.Writer      /* ERROR及以上级别日志的输出目标，为nil时与out相同 */
+	routes        []route        /* 按级别的输出路由，未匹配任何路由的日志使用out及errOut */
+	tagRoutes     []tagRoute     /* 标志的专用输出目标，日志同时输出到默认目标 */
+	formatter     Formatter      /* 日志格式 */
+	tagFormatters []tagFormatter /* 标志使用的格式 */
+	filter        messageFilter  /* 日志内容过滤规则 */
+	filters       []Filter       /* 自定义过滤规则 */
+	redactor      redactor       /* 敏感信息脱敏规则 */
+	sites         sync.Map       /* Oncef、Everyf各调用处的执行次数，uintptr -> *uint64 */
+	sampler       *sampler       /* 采样规则，为nil时不采样 */
+	callerSkip    int32          /* 解析调用者时额外跳过的层数，原子读写 */
+	noCaller      uint32         /* 为1时不解析调用者，原子读写 */
+	fileLevels    atomic.Value   /* 指定源文件日志级别，map[string]uint8，修改时整体替换 */
+	latency       latencyStats   /* 输出耗时的统计 */
+	handler       Handler        /* 处理流程的最后一环，为nil时按格式写入输出目标 */
+	middlewares   []Middleware   /* 处理流程的中间环节 */
+	pipeline      Handler        /* 由middlewares及handler组装的处理流程，为nil时直接写入输出目标 */
+	shedder       *shedder       /* 负载过高时的丢弃规则，为nil时不丢弃 */
+	name          string         /* GetLogger创建时的名称，非空时作为日志的标志 */
+	parent        *Logger        /* GetLogger创建的Logger的上级，为nil时不继承级别也不写入上级 */
+	levelSet      bool           /* 是否通过SetLevel单独设置了级别，未设置时继承上级的级别 */
+	propagate     bool           /* 日志是否同时写入上级的输出目标 */
+	exitCode      int32          /* Fatal退出进程时的退出码，为0时使用1，原子读写 */
+	assertPanic   bool           /* Assertf失败时是否panic */
+	private       bool           /* 日志不进入最近日志缓冲及订阅，用于审计日志 */
+}
+
+/* 带缓冲的输出目标，如bufio.Writer */
+type flusher interface {
+	Flush() error
+}
+
+/* 自行格式化整条日志的输出目标，如按级别及标志分流的远端服务，Logger不再调用其Write */
+type entryWriter interface {
+	WriteEntry(e *Entry) error
+}
+
+/* 需要落盘的输出目标，如os.File */
+type syncer interface {
+	Sync() error
+}
+
+func NewLogger() *Logger {
+	l := &Logger{
+		level:     uint32(VERBOSE),
+		minLevel:  uint32(VERBOSE),
+		formatter: &TextFormatter{},
+	}
+	l.tagLevels.Store(map[string]uint8{})
+	l.fileLevels.Store(map[string]uint8{})
+	return l
+}
+
+/* 调用者信息 */
+type callerInfo struct {
+	pkg  string /* 包路径，用作默认标志 */
+	fn   string /* 函数名 */
+	file string /* 源文件完整路径 */
+	line int    /* 行号 */
+}
+
+var callerCache sync.Map /* 已解析的调用者信息，uintptr -> cachedCaller */
+
+/* 按PC缓存的调用者信息 */
+type cachedCaller struct {
+	info   callerInfo
+	helper bool /* 该PC位于Helper标记的函数中，应继续向上查找 */
+}
+
+/* 解析调用者信息，skip为0时表示caller的调用者，跳过Helper标记的函数，同一调用处只解析一次 */
+func caller(skip int) callerInfo {
+	_, c := callerAt(skip + 1)
+	return c
+}
+
+/* 同caller，同时返回调用处的PC，用作调用处的键 */
+func callerAt(skip int) (uintptr, callerInfo) {
+	var pcs [16]uintptr
+	n := 1
+	if atomic.LoadInt32(&helperCount) > 0 {
+		n = len(pcs)
+	}
+	n = runtime.Callers(skip+2, pcs[:n])
+
+	var first callerInfo
+	for i, pc := range pcs[:n] {
+		v, ok := callerCache.Load(pc)
+		if !ok {
+			v = resolvePC(pc)
+			callerCache.Store(pc, v)
+		}
+
+		c := v.(cachedCaller)
+		if !c.helper {
+			return pc, c.info
+		}
+		if i == 0 {
+			first = c.info
+		}
+	}
+	return pcs[0], first
+}
+
+/* 解析PC对应的调用者，PC可能对应多个内联的函数 */
+func resolvePC(pc uintptr) cachedCaller {
+	frames := runtime.CallersFrames([]uintptr{pc})
+	var c cachedCaller
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		c.info = callerInfo{fn: frame.Function, file: frame.File, line: frame.Line}
+		peices := strings.Split(trimPackage(frame.Function), ".")
+		if size := len(peices); size >= 2 {
+			c.info.pkg, c.info.fn = strings.Join(peices[:size-1], "/"), peices[size-1]
+		}
+
+		if c.helper = isHelper(frame.Function); !c.helper {
+			break
+		}
+	}
+	return c
+}
+
+func clearCallerCache() {
+	callerCache.Range(func(pc, _ interface{}) bool {
+		callerCache.Delete(pc)
+		return true
+	})
+}
+
+/* 以调用者信息构造日志 */
+func (c callerInfo) entry(level uint8, msg string, fields ...Field) *Entry {
+	e := newEntry(level, c.pkg, c.fn, msg, fields...)
+	e.File, e.Line = c.file, c.line
+	return e
+}
+
+/* 为该Logger的所有日志增加解析调用者时跳过的层数，供项目自行封装的日志函数使用 */
+/* 如封装函数直接调用Logger的方法，AddCallerSkip(1)后日志将显示封装函数的调用者 */
+func (l *Logger) AddCallerSkip(n int) {
+	atomic.AddInt32(&l.callerSkip, int32(n))
+}
+
+/* 解析调用者时跳过的层数，base为未封装时调用者相对logf等内部函数的层数 */
+func (l *Logger) skip(base, skip int) int {
+	return base + skip + int(atomic.LoadInt32(&l.callerSkip))
+}
+
+/* 设置是否解析调用者，默认解析 */
+/* 关闭后日志不含包名、函数、源文件及行号，未指定标志时标志为空，SetTagLevel及SetFileLevel仅对Tagged等显式的标志生效 */
+func (l *Logger) SetCaller(on bool) {
+	var v uint32
+	if !on {
+		v = 1
+	}
+	atomic.StoreUint32(&l.noCaller, v)
+}
+
+/* 按SetCaller的设置解析调用者，base及skip同Logger.skip，命名Logger以名称作为默认标志 */
+func (l *Logger) caller(base, skip int) callerInfo {
+	var c callerInfo
+	if atomic.LoadUint32(&l.noCaller) == 0 {
+		c = caller(l.skip(base, skip) + 1)
+	}
+	if l.name != "" {
+		c.pkg = l.name
+	}
+	return c
+}
+
+/* 设置日志输出级别，低于该级别的日志不会输出 */
+func (l *Logger) SetLevel(level uint8) {
+	l.mu.Lock()
+	atomic.StoreUint32(&l.level, uint32(level))
+	l.levelSet = true
+	l.updateMinLevel()
+	l.mu.Unlock()
+
+	if l == std || l.parent != nil {
+		inheritLevels()
+	}
+}
+
+/* 指定具体标志的日志级别，应小于全局级别，同时作用于该标志下的子路径 */
+func (l *Logger) SetTagLevel(level uint8, tags ...string) {
+	l.mu.Lock()
+	old := l.loadTagLevels()
+	levels := make(map[string]uint8, len(old)+len(tags))
+	for tag, lv := range old {
+		levels[tag] = lv
+	}
+	for _, tag := range tags {
+		levels[tag] = level
+	}
+	l.tagLevels.Store(levels)
+	l.updateMinLevel()
+	l.mu.Unlock()
+}
+
+func (l *Logger) loadTagLevels() map[string]uint8 {
+	return l.tagLevels.Load().(map[string]uint8)
+}
+
+func (l *Logger) loadFileLevels() map[string]uint8 {
+	return l.fileLevels.Load().(map[string]uint8)
+}
+
+/* 调用方需持有l.mu */
+func (l *Logger) updateMinLevel() {
+	min := uint8(atomic.LoadUint32(&l.level))
+	for _, levels := range []map[string]uint8{l.loadTagLevels(), l.loadFileLevels()} {
+		for _, level := range levels {
+			if level < min {
+				min = level
+			}
+		}
+	}
+	atomic.StoreUint32(&l.minLevel, uint32(min))
+}
+
+/* 快速判断指定级别的日志是否可能输出，为false时无需解析调用者 */
+func (l *Logger) mayLog(level uint8) bool {
+	return uint32(level) >= atomic.LoadUint32(&l.minLevel) || recording()
+}
+
+/* 判断是否需要构造日志：会被输出，或需要记录到最近日志缓冲 */
+func (l *Logger) wants(level uint8, tag, file string) bool {
+	return recording() || l.enabledAt(level, tag, file)
+}
+
+/* 指定源文件的日志级别，优先于标志级别，用于单独调试某个文件 */
+/* 文件可为文件名或路径后缀，如"consensus.go"、"fpay/consensus/consensus.go"，多个后缀均匹配时以最长的为准 */
+func (l *Logger) SetFileLevel(level uint8, files ...string) {
+	l.mu.Lock()
+	old := l.loadFileLevels()
+	levels := make(map[string]uint8, len(old)+len(files))
+	for file, lv := range old {
+		levels[file] = lv
+	}
+	for _, file := range files {
+		levels[file] = level
+	}
+	l.fileLevels.Store(levels)
+	l.updateMinLevel()
+	l.mu.Unlock()
+}
+
+/* 设置日志输出目标，默认输出到标准库log的输出目标 */
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	l.out = w
+	l.mu.Unlock()
+}
+
+/* 设置日志格式 */
+func (l *Logger) SetFormatter(f Formatter) {
+	l.mu.Lock()
+	l.formatter = f
+	l.mu.Unlock()
+}
+
+/* 设置ERROR及以上级别日志的输出目标，为nil时与其他级别输出到同一目标 */
+func (l *Logger) SetErrorOutput(w io.Writer) {
+	l.mu.Lock()
+	l.errOut = w
+	l.mu.Unlock()
+}
+
+/* ERROR及以上级别输出到标准错误，其他级别输出到标准输出，便于容器平台区分错误流 */
+func (l *Logger) SplitOutput() {
+	l.mu.Lock()
+	l.out = os.Stdout
+	l.errOut = os.Stderr
+	l.mu.Unlock()
+}
+
+/* 返回当前的日志格式 */
+func (l *Logger) Formatter() Formatter {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.formatter
+}
+
+/* 返回指定级别日志的输出目标，调用方需持有l.mu的读锁 */
+func (l *Logger) writerFor(level uint8) io.Writer {
+	if l.errOut != nil && level >= ERROR {
+		return l.errOut
+	}
+
+	if l.out == nil {
+		return log.Writer()
+	}
+	return l.out
+}
+
+/* 返回指定级别日志匹配的路由目标，没有匹配时返回默认输出目标，另加上标志的专用输出目标 */
+/* 调用方需持有l.mu的读锁 */
+func (l *Logger) routesFor(level uint8, tag string) []io.Writer {
+	var ws []io.Writer
+	for _, r := range l.routes {
+		if level >= r.min && level <= r.max {
+			ws = append(ws, r.w)
+		}
+	}
+
+	if ws == nil {
+		ws = []io.Writer{l.writerFor(level)}
+	}
+
+	for _, r := range l.tagRoutes {
+		if matchTag(tag, r.tag) {
+			ws = appendWriter(ws, r.w)
+		}
+	}
+	return ws
+}
+
+func appendWriter(ws []io.Writer, w io.Writer) []io.Writer {
+	for _, exist := range ws {
+		if exist == w {
+			return ws
+		}
+	}
+	return append(ws, w)
+}
+
+/* 返回所有不重复的输出目标 */
+func (l *Logger) writers() []io.Writer {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ws := []io.Writer{l.writerFor(INFO)}
+	ws = appendWriter(ws, l.writerFor(ERROR))
+	for _, r := range l.routes {
+		ws = appendWriter(ws, r.w)
+	}
+	for _, r := range l.tagRoutes {
+		ws = appendWriter(ws, r.w)
+	}
+	return ws
+}
+
+/* 将缓冲中的日志写出并落盘，应在进程退出前调用以免丢失日志 */
+func (l *Logger) Sync() error {
+	var err error
+	for _, w := range l.writers() {
+		if ferr := flushWriter(w); ferr != nil && err == nil {
+			err = ferr
+		}
+		if serr := syncWriter(w); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+/* 写出缓冲的日志并关闭输出目标，标准输出及标准错误不会被关闭 */
+/* 关闭后该Logger的日志将被丢弃，适合在main()中defer调用 */
+func (l *Logger) Close() error {
+	err := l.Sync()
+
+	ws := l.writers()
+	l.mu.Lock()
+	l.out = io.Discard
+	l.errOut = nil
+	l.routes = nil
+	l.tagRoutes = nil
+	l.mu.Unlock()
+
+	for _, w := range ws {
+		if cerr := closeWriter(w); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+/* 写出带缓冲的输出目标的内容，不支持Flush时忽略 */
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+/* 落盘输出目标，不支持Sync时忽略 */
+func syncWriter(w io.Writer) error {
+	if s, ok := w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+/* 关闭输出目标，标准输出及标准错误不会被关闭 */
+func closeWriter(w io.Writer) error {
+	if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+/* 判断指定标志以指定级别记录的日志是否会输出 */
+func (l *Logger) Enabled(level uint8, tag string) bool {
+	return level >= l.tagLevel(tag)
+}
+
+/* 判断指定标志及源文件以指定级别记录的日志是否会输出，源文件级别优先于标志级别 */
+func (l *Logger) enabledAt(level uint8, tag, file string) bool {
+	if levels := l.loadFileLevels(); len(levels) > 0 && file != "" {
+		/* 从完整路径起逐级去掉开头的目录，最长的匹配优先 */
+		for name := file; ; {
+			if fileLevel, ok := levels[name]; ok {
+				return level >= fileLevel
+			}
+
+			i := strings.IndexByte(name, '/')
+			if i < 0 {
+				break
+			}
+			name = name[i+1:]
+		}
+	}
+	return l.Enabled(level, tag)
+}
+
+/* 返回标志的日志级别，未指定时依次查找上级路径(a/b/c -> a/b -> a)，均未指定时为全局级别 */
+func (l *Logger) tagLevel(tag string) uint8 {
+	levels := l.loadTagLevels()
+	if len(levels) > 0 {
+		for {
+			if level, ok := levels[tag]; ok {
+				return level
+			}
+
+			i := strings.LastIndexByte(tag, '/')
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return uint8(atomic.LoadUint32(&l.level))
+}
+
+func (l *Logger) logf(skip int, level uint8, format string, v ...interface{}) {
+	if !l.mayLog(level) {
+		return
+	}
+
+	c := l.caller(2, skip)
+	if l.wants(level, c.pkg, c.file) {
+		msg, fields := sprintf(format, v)
+		l.output(c.entry(level, msg, fields...))
+	}
+}
+
+func (l *Logger) logln(skip int, level uint8, v ...interface{}) {
+	if !l.mayLog(level) {
+		return
+	}
+
+	c := l.caller(2, skip)
+	if l.wants(level, c.pkg, c.file) {
+		l.output(c.entry(level, strings.TrimSuffix(fmt.Sprintln(resolve(v)...), "\n")))
+	}
+}
+
+func (l *Logger) logw(skip int, level uint8, msg string, fields []Field) {
+	if !l.mayLog(level) {
+		return
+	}
+
+	c := l.caller(2, skip)
+	if l.wants(level, c.pkg, c.file) {
+		l.output(c.entry(level, msg, fields...))
+	}
+}
+
+func (l *Logger) logef(skip int, level uint8, err error, format string, v []interface{}) {
+	if !l.mayLog(level) {
+		return
+	}
+
+	c := l.caller(2, skip)
+	if l.wants(level, c.pkg, c.file) {
+		msg, _ := sprintf(format, v) /* 已附加err，不再重复附加%w包装的错误 */
+		l.output(c.entry(level, msg, Err(err)))
+	}
+}
+
+/* 输出一条由调用方构造的日志，按e.Tag进行级别过滤，供适配其他日志接口使用 */
+/* e.Tag为空时命名Logger以名称作为标志 */
+func (l *Logger) LogEntry(e *Entry) {
+	if e.Tag == "" && l.name != "" {
+		e.Tag = l.name
+	}
+	if !l.wants(e.Level, e.Tag, e.File) {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.output(e)
+}
+
+/* 格式化并输出一条日志 */
+func (l *Logger) output(e *Entry) {
+	l.emit(e, false)
+}
+
+/* force为真时跳过级别过滤，用于上下文携带的级别，返回日志是否交给了处理流程，即未被过滤、采样或丢弃 */
+func (l *Logger) emit(e *Entry, force bool) bool {
+	if globals := GlobalFields(); len(globals) > 0 {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], globals...)
+	}
+
+	l.redact(e)
+	if !l.private {
+		record(e)
+	}
+	if !force && !l.enabledAt(e.Level, e.Tag, e.File) || !l.allow(e) {
+		return false
+	}
+
+	l.mu.RLock()
+	s := l.sampler
+	h := l.pipeline
+	sh := l.shedder
+	l.mu.RUnlock()
+
+	if s != nil && e.Level < ERROR && !s.allow(e) {
+		return false
+	}
+
+	if sh != nil && l.shedding(sh, e) {
+		return false
+	}
+
+	if h == nil {
+		l.write(e)
+	} else {
+		h.Handle(e)
+	}
+
+	d := time.Since(e.Time)
+	l.latency.observe(d)
+	if sh != nil {
+		atomic.StoreInt64(&sh.latency, int64(d))
+	}
+	return true
+}
+
+/* 按格式写入输出目标 */
+func (l *Logger) write(e *Entry) {
+	l.mu.RLock()
+	f := l.formatterFor(e.Tag)
+	ws := l.routesFor(e.Level, e.Tag)
+	parent := l.parent
+	if !l.propagate {
+		parent = nil
+	}
+	l.mu.RUnlock()
+
+	l.writeTo(ws, f, e)
+
+	/* 上级按自己的格式写入自己的输出目标，不再经过上级的级别过滤及处理流程 */
+	if parent != nil {
+		parent.write(e)
+	}
+}
+
+func (l *Logger) writeTo(ws []io.Writer, f Formatter, e *Entry) {
+	if g, ok := f.(goroutineFormatter); ok && g.wantsGoroutine() && e.Goroutine == 0 {
+		e.Goroutine = goroutineID()
+	}
+
+	buf := getBuffer()
+	f.Format(buf, e)
+	if _, ok := f.(binaryFormatter); !ok {
+		if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+
+	l.wmu.Lock()
+	for _, w := range ws {
+		if ef, ok := w.(entryFilter); ok && !ef.allowEntry(e) {
+			continue
+		}
+		if ew, ok := w.(entryWriter); ok {
+			ew.WriteEntry(e)
+		} else {
+			w.Write(buf.Bytes())
+		}
+	}
+	l.wmu.Unlock()
+
+	putBuffer(buf)
+}
+
+func (l *Logger) Logf(level uint8, format string, v ...interface{}) {
+	l.logf(0, level, format, v...)
+}
+
+func (l *Logger) Logln(level uint8, v ...interface{}) {
+	l.logln(0, level, v...)
+}
+
+/* 与Logf相同，解析调用者时额外跳过depth层，用于一次性的封装调用 */
+func (l *Logger) LogfDepth(depth int, level uint8, format string, v ...interface{}) {
+	l.logf(depth, level, format, v...)
+}
+
+/* 与Logln相同，解析调用者时额外跳过depth层，用于一次性的封装调用 */
+func (l *Logger) LoglnDepth(depth int, level uint8, v ...interface{}) {
+	l.logln(depth, level, v...)
+}
+
+/* 输出带结构化字段的日志，msg不会被当作格式串 */
+func (l *Logger) Logw(level uint8, msg string, fields ...Field) {
+	l.logw(0, level, msg, fields)
+}
+
+/* 以ERROR级别输出日志及err的错误链 */
+func (l *Logger) Errorw(msg string, err error, fields ...Field) {
+	l.logw(0, ERROR, msg, append([]Field{Err(err)}, fields...))
+}
+
+func (l *Logger) Verbosef(format string, v ...interface{}) {
+	l.logf(0, VERBOSE, format, v...)
+}
+
+func (l *Logger) Verboseln(v ...interface{}) {
+	l.logln(0, VERBOSE, v...)
+}
+
+func (l *Logger) Tracef(format string, v ...interface{}) {
+	l.logf(0, TRACE, format, v...)
+}
+
+func (l *Logger) Traceln(v ...interface{}) {
+	l.logln(0, TRACE, v...)
+}
+
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.logf(0, DEBUG, format, v...)
+}
+
+func (l *Logger) Debugln(v ...interface{}) {
+	l.logln(0, DEBUG, v...)
+}
+
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.logf(0, INFO, format, v...)
+}
+
+func (l *Logger) Infoln(v ...interface{}) {
+	l.logln(0, INFO, v...)
+}
+
+func (l *Logger) Warningf(format string, v ...interface{}) {
+	l.logf(0, WARNING, format, v...)
+}
+
+func (l *Logger) Warningln(v ...interface{}) {
+	l.logln(0, WARNING, v...)
+}
+
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.logf(0, ERROR, format, v...)
+}
+
+func (l *Logger) Errorln(v ...interface{}) {
+	l.logln(0, ERROR, v...)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.logf(0, FATAL, format, v...)
+	l.Sync()
+	Exit(l.fatalCode())
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (l *Logger) Fatalln(v ...interface{}) {
+	l.logln(0, FATAL, v...)
+	l.Sync()
+	Exit(l.fatalCode())
+}
+
+/* 以VERBOSE级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Verbose(msg string, fields ...Field) {
+	l.logw(0, VERBOSE, msg, fields)
+}
+
+/* 以TRACE级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Trace(msg string, fields ...Field) {
+	l.logw(0, TRACE, msg, fields)
+}
+
+/* 以DEBUG级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.logw(0, DEBUG, msg, fields)
+}
+
+/* 以INFO级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.logw(0, INFO, msg, fields)
+}
+
+/* 以WARNING级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Warning(msg string, fields ...Field) {
+	l.logw(0, WARNING, msg, fields)
+}
+
+/* 以ERROR级别输出日志，msg原样输出，不会被当作格式串 */
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.logw(0, ERROR, msg, fields)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程，msg原样输出，不会被当作格式串 */
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.logw(0, FATAL, msg, fields)
+	l.Sync()
+	Exit(l.fatalCode())
+}
@@ -0,0 +1,178 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Loki推送的配置 */
+type LokiConfig struct {
+	URL        string            /* 推送地址，如http://loki:3100/loki/api/v1/push */
+	Labels     map[string]string /* 附加到所有流的固定标签，如{"host": "node1", "app": "gateway"} */
+	Header     http.Header       /* 附加的请求头，如多租户的X-Scope-OrgID */
+	Formatter  Formatter         /* 日志行的格式，为nil时为不着色的TextFormatter */
+	BatchSize  int               /* 每批最多的条数，默认100 */
+	Interval   time.Duration     /* 定时推送的间隔，默认1秒 */
+	Retries    int               /* 失败后的重试次数 */
+	Backoff    time.Duration     /* 首次重试前的等待时间，之后每次加倍，默认1秒 */
+	MaxBackoff time.Duration     /* 等待时间的上限，默认30秒 */
+	Client     *http.Client      /* 为nil时使用http.DefaultClient */
+	Spill      *SpillFile        /* 不为nil时推送最终失败或队列已满的日志写入该暂存文件，否则丢弃 */
+}
+
+/* 批量推送到Grafana Loki的输出目标，每条日志以level、tag及配置的固定标签区分流 */
+type LokiWriter struct {
+	cfg   LokiConfig
+	batch *batcher
+}
+
+func NewLokiWriter(cfg LokiConfig) *LokiWriter {
+	if cfg.Formatter == nil {
+		cfg.Formatter = &TextFormatter{NoColor: true}
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	w := &LokiWriter{cfg: cfg}
+	w.batch = newBatcher(cfg.BatchSize, cfg.Interval, cfg.Spill, w.push)
+	return w
+}
+
+func (w *LokiWriter) WriteEntry(e *Entry) error {
+	buf := getBuffer()
+	w.cfg.Formatter.Format(buf, e)
+	line := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	err := w.batch.add(batchItem{time: e.Time, level: e.Level, tag: e.Tag, line: append([]byte(nil), line...)})
+	putBuffer(buf)
+	return err
+}
+
+/* 未经Logger写入的内容按行推送，level标签为unknown */
+func (w *LokiWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range bytes.Split(bytes.TrimSuffix(p, []byte("\n")), []byte("\n")) {
+		if aerr := w.batch.add(batchItem{time: now, level: SILENCE, line: append([]byte(nil), line...)}); err == nil {
+			err = aerr
+		}
+	}
+	return len(p), err
+}
+
+/* 重新推送暂存文件中的日志，应在远端恢复后调用 */
+func (w *LokiWriter) Replay() error {
+	return w.batch.replay()
+}
+
+/* 等待推送的日志条数 */
+func (w *LokiWriter) QueueDepth() int {
+	return w.batch.queueDepth()
+}
+
+/* 推送缓冲的日志并等待完成 */
+func (w *LokiWriter) Flush() error {
+	return w.batch.flush()
+}
+
+/* 推送剩余的日志并停止定时推送 */
+func (w *LokiWriter) Close() error {
+	return w.batch.close()
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (w *LokiWriter) labels(item batchItem) map[string]string {
+	labels := make(map[string]string, len(w.cfg.Labels)+2)
+	for k, v := range w.cfg.Labels {
+		labels[k] = v
+	}
+
+	labels["level"] = "unknown"
+	if item.level != SILENCE {
+		labels["level"] = strings.ToLower(LogLevelNames[item.level])
+	}
+	if item.tag != "" {
+		labels["tag"] = item.tag
+	}
+	return labels
+}
+
+/* 标签集合的唯一键 */
+func lokiKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func (w *LokiWriter) push(items []batchItem) error {
+	var streams []*lokiStream
+	index := make(map[string]*lokiStream)
+	for _, item := range items {
+		labels := w.labels(item)
+		key := lokiKey(labels)
+		s, ok := index[key]
+		if !ok {
+			s = &lokiStream{Stream: labels}
+			index[key] = s
+			streams = append(streams, s)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(item.time.UnixNano(), 10), string(item.line)})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	for k, vs := range w.cfg.Header {
+		header[k] = vs
+	}
+	_, err = postWithRetry(w.cfg.Client, w.cfg.URL, header, body, w.cfg.Retries, w.cfg.Backoff, w.cfg.MaxBackoff)
+	return err
+}
@@ -0,0 +1,146 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLokiWriter(t *testing.T) {
+	var mu sync.Mutex
+	var pushes []map[string][]lokiStream
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.Header.Get("X-Scope-OrgID") != "team-a" {
+			t.Errorf("missing tenant header")
+		}
+
+		var body map[string][]lokiStream
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		pushes = append(pushes, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	lw := NewLokiWriter(LokiConfig{
+		URL:      srv.URL,
+		Labels:   map[string]string{"host": "node1"},
+		Header:   http.Header{"X-Scope-OrgID": {"team-a"}},
+		Interval: time.Hour,
+		Retries:  1,
+		Backoff:  time.Millisecond,
+	})
+
+	l := NewLogger()
+	l.SetOutput(lw)
+	l.Infoln("one")
+	l.Infoln("two")
+	l.Tagged("p2p").Errorln("three")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pushes) != 1 || len(pushes[0]["streams"]) != 2 {
+		t.Fatalf("pushes = %+v", pushes)
+	}
+	info, errs := pushes[0]["streams"][0], pushes[0]["streams"][1]
+	if info.Stream["level"] != "info" || info.Stream["host"] != "node1" || len(info.Values) != 2 {
+		t.Errorf("info stream = %+v", info)
+	}
+	if errs.Stream["level"] != "error" || errs.Stream["tag"] != "p2p" || !strings.HasSuffix(errs.Values[0][1], "] three") {
+		t.Errorf("error stream = %+v", errs)
+	}
+}
+
+func TestLokiWriterQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	var failing int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	lw := NewLokiWriter(LokiConfig{URL: srv.URL, BatchSize: 1, Interval: time.Hour})
+	e := &Entry{Time: time.Now(), Level: INFO, Message: "m"}
+
+	/* 远端阻塞时写入不应阻塞，队列满后报告错误 */
+	var err error
+	for i := 0; i < 20 && err == nil; i++ {
+		err = lw.WriteEntry(e)
+	}
+	if err != errQueueFull {
+		t.Fatalf("err = %v, want errQueueFull", err)
+	}
+
+	/* 远端失败后写入报告该错误 */
+	atomic.StoreInt32(&failing, 1)
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for err = lw.WriteEntry(e); err == nil || err == errQueueFull; err = lw.WriteEntry(e) {
+		if time.Now().After(deadline) {
+			t.Fatal("push failure not reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	lw.Close()
+	if err := lw.WriteEntry(e); err != errBatcherClosed {
+		t.Errorf("WriteEntry after Close = %v", err)
+	}
+	if _, err := lw.Write([]byte("x\n")); err != errBatcherClosed {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestSleepBackoff(t *testing.T) {
+	backoff := time.Millisecond
+	for i := 0; i < 4; i++ {
+		backoff = sleepBackoff(backoff, 3*time.Millisecond)
+	}
+	if backoff != 3*time.Millisecond {
+		t.Errorf("backoff = %v, want the 3ms cap", backoff)
+	}
+}
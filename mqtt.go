@@ -0,0 +1,356 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* MQTT发布的配置 */
+type MQTTConfig struct {
+	Addr        string        /* 代理地址，如mqtt:1883 */
+	ClientID    string        /* 客户端标识，默认为zlog-<主机名>-<进程号> */
+	Username    string        /* 不为空时以用户名及密码认证 */
+	Password    string        /* 用户名认证的密码 */
+	Prefix      string        /* 主题前缀，日志发布到<Prefix>/<Host>/<级别>，如logs/node1/error，默认logs */
+	Host        string        /* 主题中的主机名，默认为os.Hostname() */
+	QoS         byte          /* 发布的服务质量，0或1，为1时等待代理确认 */
+	WillTopic   string        /* 不为空时设置遗嘱，连接异常断开时代理向该主题发布WillMessage */
+	WillMessage string        /* 遗嘱内容，如"offline" */
+	WillRetain  bool          /* 代理保留遗嘱消息 */
+	KeepAlive   time.Duration /* 心跳间隔，代理超过1.5倍间隔未收到报文时断开连接并发布遗嘱，为0时不发送心跳 */
+	Formatter   Formatter     /* 消息的格式，默认为JSONFormatter */
+	BatchSize   int           /* 每批最多的条数，默认100 */
+	Interval    time.Duration /* 定时发送的间隔，默认1秒 */
+	Timeout     time.Duration /* 连接、发送及等待确认的超时时间，默认5秒 */
+	Spill       *SpillFile    /* 不为nil时发送失败或队列已满的日志写入该暂存文件，否则丢弃 */
+}
+
+/* MQTT 3.1.1报文类型 */
+const (
+	mqttConnect    = 1 << 4
+	mqttConnack    = 2 << 4
+	mqttPublish    = 3 << 4
+	mqttPuback     = 4 << 4
+	mqttPingreq    = 12 << 4
+	mqttDisconnect = 14 << 4
+)
+
+/* 批量发布到MQTT代理的输出目标，用于已有MQTT连接的边缘设备，连接断开后在下一批发送时重连 */
+type MQTTWriter struct {
+	cfg    MQTTConfig
+	batch  *batcher
+	mu     sync.Mutex /* 保护以下连接状态 */
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID uint16 /* 下一个QoS 1报文的标识 */
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func NewMQTTWriter(cfg MQTTConfig) *MQTTWriter {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "logs"
+	}
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "zlog-" + cfg.Host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if cfg.QoS > 1 {
+		cfg.QoS = 1
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = &JSONFormatter{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	w := &MQTTWriter{cfg: cfg, stop: make(chan struct{})}
+	w.batch = newBatcher(cfg.BatchSize, cfg.Interval, cfg.Spill, w.send)
+	if cfg.KeepAlive > 0 {
+		go w.pingLoop()
+	}
+	return w
+}
+
+func (w *MQTTWriter) WriteEntry(e *Entry) error {
+	buf := getBuffer()
+	w.cfg.Formatter.Format(buf, e)
+	line := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	err := w.batch.add(batchItem{time: e.Time, level: e.Level, tag: e.Tag, line: append([]byte(nil), line...)})
+	putBuffer(buf)
+	return err
+}
+
+/* 未经Logger写入的内容按行发布到<Prefix>/<Host>/unknown */
+func (w *MQTTWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range bytes.Split(bytes.TrimSuffix(p, []byte("\n")), []byte("\n")) {
+		if aerr := w.batch.add(batchItem{time: now, level: SILENCE, line: append([]byte(nil), line...)}); err == nil {
+			err = aerr
+		}
+	}
+	return len(p), err
+}
+
+/* 重新发送暂存文件中的日志，应在代理恢复后调用 */
+func (w *MQTTWriter) Replay() error {
+	return w.batch.replay()
+}
+
+/* 等待发送的日志条数 */
+func (w *MQTTWriter) QueueDepth() int {
+	return w.batch.queueDepth()
+}
+
+/* 发送缓冲的日志并等待完成 */
+func (w *MQTTWriter) Flush() error {
+	return w.batch.flush()
+}
+
+/* 发送剩余的日志并正常断开连接，正常断开时代理不会发布遗嘱 */
+func (w *MQTTWriter) Close() error {
+	err := w.batch.close()
+	w.once.Do(func() { close(w.stop) })
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		w.conn.Write([]byte{mqttDisconnect, 0})
+		w.disconnect()
+	}
+	return err
+}
+
+/* 日志的发布主题，如logs/node1/error */
+func (w *MQTTWriter) topic(item batchItem) string {
+	level := "unknown"
+	if item.level != SILENCE {
+		level = strings.ToLower(LogLevelNames[item.level])
+	}
+	return w.cfg.Prefix + "/" + w.cfg.Host + "/" + level
+}
+
+func (w *MQTTWriter) pingLoop() {
+	ticker := time.NewTicker(w.cfg.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.conn != nil {
+				w.conn.SetWriteDeadline(time.Now().Add(w.cfg.Timeout))
+				if _, err := w.conn.Write([]byte{mqttPingreq, 0}); err != nil {
+					w.disconnect()
+				}
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+/* 以MQTT的变长编码追加剩余长度 */
+func mqttLength(buf *bytes.Buffer, n int) {
+	for {
+		b := byte(n % 128)
+		if n /= 128; n > 0 {
+			b |= 0x80
+		}
+		buf.WriteByte(b)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+/* 追加以两字节长度开头的字符串 */
+func mqttString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+/* 追加报文，body为可变头及载荷 */
+func mqttPacket(buf *bytes.Buffer, header byte, body []byte) {
+	buf.WriteByte(header)
+	mqttLength(buf, len(body))
+	buf.Write(body)
+}
+
+/* 读取一个报文，返回首字节及剩余内容 */
+func mqttRead(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	n, shift := 0, 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("zlog: malformed mqtt packet length")
+		}
+	}
+
+	body := make([]byte, n)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+/* 建立连接并完成握手，调用方需持有w.mu */
+func (w *MQTTWriter) connect() error {
+	conn, err := net.DialTimeout("tcp", w.cfg.Addr, w.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+
+	var body bytes.Buffer
+	mqttString(&body, "MQTT")
+	body.WriteByte(4)
+
+	flags := byte(0x02)
+	if w.cfg.WillTopic != "" {
+		flags |= 0x04 | w.cfg.QoS<<3
+		if w.cfg.WillRetain {
+			flags |= 0x20
+		}
+	}
+	if w.cfg.Username != "" {
+		flags |= 0xc0
+	}
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(w.cfg.KeepAlive/time.Second))
+
+	mqttString(&body, w.cfg.ClientID)
+	if w.cfg.WillTopic != "" {
+		mqttString(&body, w.cfg.WillTopic)
+		mqttString(&body, w.cfg.WillMessage)
+	}
+	if w.cfg.Username != "" {
+		mqttString(&body, w.cfg.Username)
+		mqttString(&body, w.cfg.Password)
+	}
+
+	var packet bytes.Buffer
+	mqttPacket(&packet, mqttConnect, body.Bytes())
+	if _, err := conn.Write(packet.Bytes()); err != nil {
+		conn.Close()
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	header, ack, err := mqttRead(r)
+	switch {
+	case err != nil:
+		conn.Close()
+		return err
+	case header&0xf0 != mqttConnack || len(ack) != 2:
+		conn.Close()
+		return fmt.Errorf("zlog: unexpected mqtt packet 0x%02x", header)
+	case ack[1] != 0:
+		conn.Close()
+		return fmt.Errorf("zlog: mqtt connection refused, code %d", ack[1])
+	}
+
+	w.conn, w.r = conn, r
+	return nil
+}
+
+/* 调用方需持有w.mu */
+func (w *MQTTWriter) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn, w.r = nil, nil
+	}
+}
+
+func (w *MQTTWriter) send(items []batchItem) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	pending := make(map[uint16]bool)
+	for _, item := range items {
+		var body bytes.Buffer
+		mqttString(&body, w.topic(item))
+		if w.cfg.QoS == 1 {
+			if w.nextID++; w.nextID == 0 {
+				w.nextID = 1
+			}
+			binary.Write(&body, binary.BigEndian, w.nextID)
+			pending[w.nextID] = true
+		}
+		body.Write(item.line)
+		mqttPacket(&buf, mqttPublish|w.cfg.QoS<<1, body.Bytes())
+	}
+
+	w.conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		w.disconnect()
+		return err
+	}
+
+	/* QoS 1等待每条消息的PUBACK，期间的PINGRESP等报文被忽略 */
+	for len(pending) > 0 {
+		header, body, err := mqttRead(w.r)
+		if err != nil {
+			w.disconnect()
+			return err
+		}
+		if header&0xf0 == mqttPuback && len(body) == 2 {
+			delete(pending, binary.BigEndian.Uint16(body))
+		}
+	}
+	return nil
+}
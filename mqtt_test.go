@@ -0,0 +1,151 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/* 最简的MQTT代理，记录CONNECT报文及收到的消息，确认QoS 1的消息 */
+type fakeBroker struct {
+	ln      net.Listener
+	wg      sync.WaitGroup /* 等待连接处理结束 */
+	mu      sync.Mutex
+	connect []byte
+	msgs    []string /* 主题 消息 */
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &fakeBroker{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.wg.Add(1)
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer b.wg.Done()
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := mqttRead(r)
+		if err != nil {
+			return
+		}
+
+		switch header & 0xf0 {
+		case mqttConnect:
+			b.mu.Lock()
+			b.connect = body
+			b.mu.Unlock()
+			conn.Write([]byte{mqttConnack, 2, 0, 0})
+		case mqttPublish:
+			n := int(binary.BigEndian.Uint16(body))
+			topic, rest := string(body[2:2+n]), body[2+n:]
+			if qos := header >> 1 & 3; qos == 1 {
+				conn.Write([]byte{mqttPuback, 2, rest[0], rest[1]})
+				rest = rest[2:]
+			}
+			b.mu.Lock()
+			b.msgs = append(b.msgs, topic+" "+string(rest))
+			b.mu.Unlock()
+		case mqttDisconnect:
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) snapshot() ([]byte, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.connect, append([]string(nil), b.msgs...)
+}
+
+func TestMQTTWriter(t *testing.T) {
+	for _, qos := range []byte{0, 1} {
+		b := newFakeBroker(t)
+		w := NewMQTTWriter(MQTTConfig{
+			Addr:        b.ln.Addr().String(),
+			Host:        "edge7",
+			QoS:         qos,
+			Username:    "device",
+			Password:    "pw",
+			WillTopic:   "status/edge7",
+			WillMessage: "offline",
+		})
+
+		l := NewLogger()
+		l.SetOutput(w)
+		l.Errorln("sensor offline")
+		l.Infoln("sampling")
+		if err := w.Flush(); err != nil {
+			t.Fatalf("qos %d: %v", qos, err)
+		}
+		w.Close()
+		b.wg.Wait()
+
+		connect, msgs := b.snapshot()
+		if len(msgs) != 2 || !strings.HasPrefix(msgs[0], "logs/edge7/error {") || !strings.Contains(msgs[0], `"message":"sensor offline"`) || !strings.HasPrefix(msgs[1], "logs/edge7/info ") {
+			t.Errorf("qos %d: %q", qos, msgs)
+		}
+		if flags := connect[7]; flags != 0xc6|qos<<3 {
+			t.Errorf("qos %d: connect flags %08b", qos, flags)
+		}
+		for _, want := range []string{"status/edge7", "offline", "device", "pw"} {
+			if !bytes.Contains(connect, []byte(want)) {
+				t.Errorf("qos %d: connect missing %q", qos, want)
+			}
+		}
+		b.ln.Close()
+	}
+}
+
+func TestMQTTLength(t *testing.T) {
+	for n, want := range map[int][]byte{0: {0}, 127: {0x7f}, 128: {0x80, 1}, 16383: {0xff, 0x7f}, 2097152: {0x80, 0x80, 0x80, 1}} {
+		var buf bytes.Buffer
+		mqttLength(&buf, n)
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%d: % x", n, buf.Bytes())
+		}
+	}
+}
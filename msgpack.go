@@ -0,0 +1,415 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+/* MessagePack格式，每条日志编码为一个map，记录之间没有分隔符，键及值如下： */
+/* "t" 记录时间，Unix纳秒；"l" 日志级别；"g" 标志；"c" 调用者函数名；"m" 日志内容 */
+/* "s" 调用者源文件及"n" 行号，未知时省略；"f" 结构化字段map，没有时省略 */
+/* 字段值为nil、bool、整数、浮点数、str或bin，其他类型转为str；可使用NewMsgpackDecoder解码 */
+type MsgpackFormatter struct{}
+
+func (f *MsgpackFormatter) binary() {}
+
+func (f *MsgpackFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	n := 5
+	if e.File != "" {
+		n += 2
+	}
+	if len(e.Fields) > 0 {
+		n++
+	}
+
+	mpMap(buf, n)
+	mpString(buf, "t")
+	mpInt(buf, e.Time.UnixNano())
+	mpString(buf, "l")
+	mpUint(buf, uint64(e.Level))
+	mpString(buf, "g")
+	mpString(buf, e.Tag)
+	mpString(buf, "c")
+	mpString(buf, e.Func)
+	if e.File != "" {
+		mpString(buf, "s")
+		mpString(buf, e.File)
+		mpString(buf, "n")
+		mpInt(buf, int64(e.Line))
+	}
+	mpString(buf, "m")
+	mpString(buf, e.Message)
+
+	if len(e.Fields) > 0 {
+		mpString(buf, "f")
+		mpMap(buf, len(e.Fields))
+		for _, field := range e.Fields {
+			mpString(buf, field.Key)
+			mpValue(buf, field.Value)
+		}
+	}
+}
+
+func mpValue(buf *bytes.Buffer, v interface{}) {
+	switch val := jsonValue(v).(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		mpInt(buf, int64(val))
+	case int8:
+		mpInt(buf, int64(val))
+	case int16:
+		mpInt(buf, int64(val))
+	case int32:
+		mpInt(buf, int64(val))
+	case int64:
+		mpInt(buf, val)
+	case uint:
+		mpUint(buf, uint64(val))
+	case uint8:
+		mpUint(buf, uint64(val))
+	case uint16:
+		mpUint(buf, uint64(val))
+	case uint32:
+		mpUint(buf, uint64(val))
+	case uint64:
+		mpUint(buf, val)
+	case float32:
+		buf.WriteByte(0xca)
+		mpBig(buf, 4, uint64(math.Float32bits(val)))
+	case float64:
+		buf.WriteByte(0xcb)
+		mpBig(buf, 8, math.Float64bits(val))
+	case string:
+		mpString(buf, val)
+	case []byte:
+		mpHeader(buf, len(val), 0, 0, 0xc4, 0xc5, 0xc6)
+		buf.Write(val)
+	default:
+		mpString(buf, fmt.Sprint(val))
+	}
+}
+
+/* 以大端序写入n字节 */
+func mpBig(buf *bytes.Buffer, n int, v uint64) {
+	for i := n - 1; i >= 0; i-- {
+		buf.WriteByte(byte(v >> (8 * uint(i))))
+	}
+}
+
+/* 写入长度头，fixMax为0时没有fix形式 */
+func mpHeader(buf *bytes.Buffer, n, fixMax int, fix, b8, b16, b32 byte) {
+	switch {
+	case n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint8 && b8 != 0:
+		buf.WriteByte(b8)
+		mpBig(buf, 1, uint64(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		mpBig(buf, 2, uint64(n))
+	default:
+		buf.WriteByte(b32)
+		mpBig(buf, 4, uint64(n))
+	}
+}
+
+func mpString(buf *bytes.Buffer, s string) {
+	mpHeader(buf, len(s), 32, 0xa0, 0xd9, 0xda, 0xdb)
+	buf.WriteString(s)
+}
+
+func mpMap(buf *bytes.Buffer, n int) {
+	mpHeader(buf, n, 16, 0x80, 0, 0xde, 0xdf)
+}
+
+func mpUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 128:
+		buf.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		mpBig(buf, 1, v)
+	case v <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		mpBig(buf, 2, v)
+	case v <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		mpBig(buf, 4, v)
+	default:
+		buf.WriteByte(0xcf)
+		mpBig(buf, 8, v)
+	}
+}
+
+func mpInt(buf *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0:
+		mpUint(buf, uint64(v))
+	case v >= -32:
+		buf.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		mpBig(buf, 1, uint64(v))
+	case v >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		mpBig(buf, 2, uint64(v))
+	case v >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		mpBig(buf, 4, uint64(v))
+	default:
+		buf.WriteByte(0xd3)
+		mpBig(buf, 8, uint64(v))
+	}
+}
+
+var errMsgpackEntry = errors.New("zlog: malformed msgpack entry")
+
+const maxMsgpackLength = 16 << 20 /* 解码时允许的最大字符串、二进制长度及数组、map元素数，避免损坏的长度头导致过量分配 */
+
+/* 解码MsgpackFormatter输出的日志流 */
+type MsgpackDecoder struct {
+	r *bufio.Reader
+}
+
+func NewMsgpackDecoder(r io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{r: bufio.NewReader(r)}
+}
+
+/* 解码下一条日志，没有更多日志时返回io.EOF */
+func (d *MsgpackDecoder) Decode() (*Entry, error) {
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.([]Field)
+	if !ok {
+		return nil, errMsgpackEntry
+	}
+
+	e := new(Entry)
+	for _, kv := range m {
+		switch kv.Key {
+		case "t":
+			ns, ok := kv.Value.(int64)
+			if !ok {
+				return nil, errMsgpackEntry
+			}
+			e.Time = time.Unix(0, ns)
+		case "l":
+			level, ok := kv.Value.(int64)
+			if !ok || level < 0 || level > math.MaxUint8 {
+				return nil, errMsgpackEntry
+			}
+			e.Level = uint8(level)
+		case "g":
+			e.Tag, _ = kv.Value.(string)
+		case "c":
+			e.Func, _ = kv.Value.(string)
+		case "s":
+			e.File, _ = kv.Value.(string)
+		case "n":
+			line, _ := kv.Value.(int64)
+			e.Line = int(line)
+		case "m":
+			e.Message, _ = kv.Value.(string)
+		case "f":
+			e.Fields, _ = kv.Value.([]Field)
+		}
+	}
+	return e, nil
+}
+
+/* 读取n字节大端序整数 */
+func (d *MsgpackDecoder) big(n int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-n:]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+/* 解码一个值，整数均解码为int64(超出范围的uint64除外)，map解码为[]Field以保持顺序 */
+func (d *MsgpackDecoder) value() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var n uint64
+	switch {
+	case c < 0x80:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.fields(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		if n, err = d.big(1 << (c - 0xc4)); err != nil {
+			return nil, err
+		}
+		if n > maxMsgpackLength {
+			return nil, errMsgpackEntry
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return b, nil
+	case 0xca:
+		n, err = d.big(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err = d.big(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		if n, err = d.big(1 << (c - 0xcc)); err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		n, err = d.big(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err = d.big(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err = d.big(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err = d.big(8)
+		return int64(n), err
+	case 0xd9, 0xda, 0xdb:
+		if n, err = d.big(1 << (c - 0xd9)); err != nil {
+			return nil, err
+		}
+		return d.str(int(n))
+	case 0xdc, 0xdd:
+		if n, err = d.big(2 << (c - 0xdc)); err != nil {
+			return nil, err
+		}
+		return d.array(int(n))
+	case 0xde, 0xdf:
+		if n, err = d.big(2 << (c - 0xde)); err != nil {
+			return nil, err
+		}
+		return d.fields(int(n))
+	}
+	return nil, fmt.Errorf("zlog: unsupported msgpack type 0x%x", c)
+}
+
+func (d *MsgpackDecoder) str(n int) (interface{}, error) {
+	if n > maxMsgpackLength {
+		return nil, errMsgpackEntry
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return string(b), nil
+}
+
+/* 元素数来自长度头，预分配的容量以实际可能读到的元素为限 */
+func msgpackCap(n int) int {
+	if n > 1024 {
+		return 1024
+	}
+	return n
+}
+
+func (d *MsgpackDecoder) array(n int) (interface{}, error) {
+	if n > maxMsgpackLength {
+		return nil, errMsgpackEntry
+	}
+	a := make([]interface{}, 0, msgpackCap(n))
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *MsgpackDecoder) fields(n int) (interface{}, error) {
+	if n > maxMsgpackLength {
+		return nil, errMsgpackEntry
+	}
+	fields := make([]Field, 0, msgpackCap(n))
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, errMsgpackEntry
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		fields = append(fields, F(key, v))
+	}
+	return fields, nil
+}
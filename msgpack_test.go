@@ -0,0 +1,98 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&MsgpackFormatter{})
+
+	long := strings.Repeat("x", 300)
+	l.Logw(INFO, "hello", F("n", -70000), F("u", uint64(1<<40)), F("f", 1.5), F("ok", true), F("nil", nil), F("err", errors.New("boom")), F("long", long))
+	l.Warningln("second")
+
+	d := NewMsgpackDecoder(&buf)
+	e, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != INFO || e.Message != "hello" || e.Func != "TestMsgpackRoundTrip" || !strings.HasSuffix(e.File, "msgpack_test.go") || e.Line == 0 {
+		t.Errorf("decoded %+v", e)
+	}
+	if time.Since(e.Time) > time.Minute {
+		t.Errorf("time = %v", e.Time)
+	}
+
+	want := []Field{F("n", int64(-70000)), F("u", int64(1<<40)), F("f", 1.5), F("ok", true), F("nil", nil), F("err", "boom"), F("long", long)}
+	if !reflect.DeepEqual(e.Fields, want) {
+		t.Errorf("fields = %v, want %v", e.Fields, want)
+	}
+
+	if e, err = d.Decode(); err != nil || e.Message != "second" || e.Level != WARNING || e.Fields != nil {
+		t.Errorf("second = %+v, %v", e, err)
+	}
+	if _, err = d.Decode(); err != io.EOF {
+		t.Errorf("Decode() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestMsgpackTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	(&MsgpackFormatter{}).Format(&buf, newEntry(INFO, "tag", "fn", "message"))
+
+	b := buf.Bytes()
+	if _, err := NewMsgpackDecoder(bytes.NewReader(b[:len(b)-3])).Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode() = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestMsgpackHugeLength(t *testing.T) {
+	for _, b := range [][]byte{
+		{0xdb, 0xff, 0xff, 0xff, 0xff}, /* str32 */
+		{0xc6, 0xff, 0xff, 0xff, 0xff}, /* bin32 */
+		{0xdd, 0xff, 0xff, 0xff, 0xff}, /* array32 */
+		{0xdf, 0xff, 0xff, 0xff, 0xff}, /* map32 */
+	} {
+		if _, err := NewMsgpackDecoder(bytes.NewReader(b)).Decode(); err != errMsgpackEntry {
+			t.Errorf("%x: Decode() = %v, want errMsgpackEntry", b, err)
+		}
+	}
+
+	/* 长度在上限内但数据不足时不应按长度头预分配 */
+	if _, err := NewMsgpackDecoder(bytes.NewReader([]byte{0xdd, 0x00, 0xff, 0xff, 0xff})).Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode() = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,320 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* NATS发布的配置 */
+type NatsConfig struct {
+	Addr      string        /* 服务器地址，如nats:4222 */
+	Subject   string        /* 主题前缀，日志发布到<Subject>.<级别>.<标志>，如logs.error.fpay.p2p，默认logs */
+	Token     string        /* 不为空时以令牌认证 */
+	User      string        /* 不为空时以用户名及密码认证 */
+	Password  string        /* 用户名认证的密码 */
+	JetStream bool          /* 等待JetStream的确认，主题须已被某个流捕获，否则发送超时失败 */
+	Formatter Formatter     /* 消息的格式，默认为JSONFormatter */
+	BatchSize int           /* 每批最多的条数，默认100 */
+	Interval  time.Duration /* 定时发送的间隔，默认1秒 */
+	Timeout   time.Duration /* 连接、发送及等待确认的超时时间，默认5秒 */
+	Spill     *SpillFile    /* 不为nil时发送失败或队列已满的日志写入该暂存文件，否则丢弃 */
+}
+
+/* 批量发布到NATS的输出目标，主题按级别及标志区分，连接断开后在下一批发送时重连 */
+type NatsWriter struct {
+	cfg   NatsConfig
+	batch *batcher
+	mu    sync.Mutex /* 保护以下连接状态 */
+	conn  net.Conn
+	r     *bufio.Reader
+	inbox string /* JetStream确认的回复主题前缀 */
+}
+
+func NewNatsWriter(cfg NatsConfig) *NatsWriter {
+	if cfg.Subject == "" {
+		cfg.Subject = "logs"
+	}
+	if cfg.Formatter == nil {
+		cfg.Formatter = &JSONFormatter{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	w := &NatsWriter{cfg: cfg}
+	w.batch = newBatcher(cfg.BatchSize, cfg.Interval, cfg.Spill, w.send)
+	return w
+}
+
+func (w *NatsWriter) WriteEntry(e *Entry) error {
+	buf := getBuffer()
+	w.cfg.Formatter.Format(buf, e)
+	line := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	err := w.batch.add(batchItem{time: e.Time, level: e.Level, tag: e.Tag, line: append([]byte(nil), line...)})
+	putBuffer(buf)
+	return err
+}
+
+/* 未经Logger写入的内容按行发布到<Subject>.unknown */
+func (w *NatsWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var err error
+	for _, line := range bytes.Split(bytes.TrimSuffix(p, []byte("\n")), []byte("\n")) {
+		if aerr := w.batch.add(batchItem{time: now, level: SILENCE, line: append([]byte(nil), line...)}); err == nil {
+			err = aerr
+		}
+	}
+	return len(p), err
+}
+
+/* 重新发送暂存文件中的日志，应在服务器恢复后调用 */
+func (w *NatsWriter) Replay() error {
+	return w.batch.replay()
+}
+
+/* 等待发送的日志条数 */
+func (w *NatsWriter) QueueDepth() int {
+	return w.batch.queueDepth()
+}
+
+/* 发送缓冲的日志并等待完成 */
+func (w *NatsWriter) Flush() error {
+	return w.batch.flush()
+}
+
+/* 发送剩余的日志并断开连接 */
+func (w *NatsWriter) Close() error {
+	err := w.batch.close()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+/* NATS主题中不允许的字符 */
+var natsReplacer = strings.NewReplacer("/", ".", " ", "_", "\t", "_", "*", "_", ">", "_")
+
+/* 日志的发布主题，如logs.error.fpay.p2p */
+func (w *NatsWriter) subject(item batchItem) string {
+	if item.level == SILENCE {
+		return w.cfg.Subject + ".unknown"
+	}
+
+	subject := w.cfg.Subject + "." + strings.ToLower(LogLevelNames[item.level])
+	if tag := strings.Trim(natsReplacer.Replace(item.tag), "."); tag != "" {
+		subject += "." + tag
+	}
+	return subject
+}
+
+/* natsConnect的参数 */
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Protocol int    `json:"protocol"`
+	Token    string `json:"auth_token,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"pass,omitempty"`
+}
+
+/* 建立连接并完成握手，调用方需持有w.mu */
+func (w *NatsWriter) connect() error {
+	conn, err := net.DialTimeout("tcp", w.cfg.Addr, w.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+	r := bufio.NewReader(conn)
+
+	if line, err := r.ReadString('\n'); err != nil {
+		conn.Close()
+		return err
+	} else if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("zlog: unexpected nats greeting %q", strings.TrimSpace(line))
+	}
+
+	params, _ := json.Marshal(natsConnect{
+		Name: "zlog", Lang: "go", Version: "zlog", Protocol: 1,
+		Token: w.cfg.Token, User: w.cfg.User, Password: w.cfg.Password,
+	})
+	cmd := "CONNECT " + string(params) + "\r\n"
+	if w.cfg.JetStream {
+		w.inbox = "_INBOX.zlog" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		cmd += "SUB " + w.inbox + ".* 1\r\n"
+	}
+
+	w.conn, w.r = conn, r
+	if _, err := conn.Write([]byte(cmd + "PING\r\n")); err != nil {
+		w.disconnect()
+		return err
+	}
+	if err := w.waitPong(nil); err != nil {
+		w.disconnect()
+		return err
+	}
+	return nil
+}
+
+/* 调用方需持有w.mu */
+func (w *NatsWriter) disconnect() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn, w.r = nil, nil
+	}
+}
+
+/* 读取服务器的回复直至PONG，期间收到的JetStream确认交给ack处理，-ERR视为失败 */
+func (w *NatsWriter) waitPong(ack func(payload []byte) error) error {
+	for {
+		line, err := w.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := w.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("zlog: nats " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case strings.HasPrefix(line, "MSG "):
+			/* MSG <subject> <sid> [reply] <size> */
+			args := strings.Fields(line)
+			size, err := strconv.Atoi(args[len(args)-1])
+			if err != nil {
+				return fmt.Errorf("zlog: malformed nats message %q", line)
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(w.r, payload); err != nil {
+				return err
+			}
+			if ack != nil {
+				if err := ack(payload[:size]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+/* JetStream的确认 */
+type natsPubAck struct {
+	Stream string `json:"stream"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+/* 空闲时不读取连接，服务器的PING得不到回复会断开连接，因此已有的连接发送失败时重连并重发一次 */
+func (w *NatsWriter) send(items []batchItem) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	reused := w.conn != nil
+	if !reused {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+
+	err := w.publish(items)
+	if err != nil && reused {
+		if err = w.connect(); err == nil {
+			err = w.publish(items)
+		}
+	}
+	return err
+}
+
+/* 发布一批日志并等待服务器处理，失败时断开连接，调用方需持有w.mu且已连接 */
+func (w *NatsWriter) publish(items []batchItem) error {
+	var buf bytes.Buffer
+	for i, item := range items {
+		buf.WriteString("PUB ")
+		buf.WriteString(w.subject(item))
+		if w.cfg.JetStream {
+			buf.WriteString(" " + w.inbox + "." + strconv.Itoa(i))
+		}
+		buf.WriteString(" " + strconv.Itoa(len(item.line)) + "\r\n")
+		buf.Write(item.line)
+		buf.WriteString("\r\n")
+	}
+
+	w.conn.SetDeadline(time.Now().Add(w.cfg.Timeout))
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		w.disconnect()
+		return err
+	}
+
+	/* 核心NATS以PING确认服务器已处理之前的PUB；JetStream需等待每条消息的确认 */
+	acked := 0
+	for {
+		if _, err := w.conn.Write([]byte("PING\r\n")); err != nil {
+			w.disconnect()
+			return err
+		}
+		err := w.waitPong(func(payload []byte) error {
+			var ack natsPubAck
+			if err := json.Unmarshal(payload, &ack); err != nil {
+				return err
+			}
+			if ack.Error != nil {
+				return fmt.Errorf("zlog: jetstream %d %s", ack.Error.Code, ack.Error.Description)
+			}
+			acked++
+			return nil
+		})
+		if err != nil {
+			w.disconnect()
+			return err
+		}
+		if !w.cfg.JetStream || acked >= len(items) {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
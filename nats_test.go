@@ -0,0 +1,184 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/* 最简的NATS服务器，记录收到的消息，jetstream为真时确认带回复主题的消息 */
+type fakeNats struct {
+	ln        net.Listener
+	jetstream bool
+	mu        sync.Mutex
+	connect   string
+	conns     []net.Conn
+	msgs      []string /* 主题 消息 */
+}
+
+func newFakeNats(t *testing.T, jetstream bool) *fakeNats {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeNats{ln: ln, jetstream: jetstream}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNats) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.Write([]byte(`INFO {"server_id":"fake","max_payload":1048576}` + "\r\n"))
+
+	r := bufio.NewReader(conn)
+	sid := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		switch args[0] {
+		case "CONNECT":
+			s.mu.Lock()
+			s.connect = strings.TrimSpace(strings.TrimPrefix(line, "CONNECT"))
+			s.mu.Unlock()
+		case "SUB":
+			sid = args[len(args)-1]
+		case "PING":
+			conn.Write([]byte("PONG\r\n"))
+		case "PUB":
+			size, _ := strconv.Atoi(args[len(args)-1])
+			payload := make([]byte, size+2)
+			io.ReadFull(r, payload)
+
+			s.mu.Lock()
+			s.msgs = append(s.msgs, args[1]+" "+string(payload[:size]))
+			s.mu.Unlock()
+
+			if s.jetstream && len(args) == 4 {
+				ack := `{"stream":"LOGS","seq":1}`
+				conn.Write([]byte("MSG " + args[2] + " " + sid + " " + strconv.Itoa(len(ack)) + "\r\n" + ack + "\r\n"))
+			}
+		}
+	}
+}
+
+func (s *fakeNats) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.msgs...)
+}
+
+func TestNatsWriter(t *testing.T) {
+	for _, jetstream := range []bool{false, true} {
+		s := newFakeNats(t, jetstream)
+		w := NewNatsWriter(NatsConfig{Addr: s.ln.Addr().String(), Token: "s3cret", JetStream: jetstream})
+
+		l := NewLogger()
+		l.SetOutput(w)
+		l.Tagged("fpay/p2p").Errorln("peer lost")
+		l.Infoln("started")
+		if err := w.Flush(); err != nil {
+			t.Fatalf("jetstream=%v: %v", jetstream, err)
+		}
+
+		msgs := s.messages()
+		if len(msgs) != 2 || !strings.HasPrefix(msgs[0], `logs.error.fpay.p2p {"time":`) || !strings.Contains(msgs[0], `"message":"peer lost"`) {
+			t.Errorf("jetstream=%v: %q", jetstream, msgs)
+		}
+		if len(msgs) == 2 && !strings.HasPrefix(msgs[1], "logs.info.github.com.atlaslee.zlog ") {
+			t.Errorf("jetstream=%v: subject %q", jetstream, msgs[1])
+		}
+		if !strings.Contains(s.connect, `"auth_token":"s3cret"`) {
+			t.Errorf("connect: %s", s.connect)
+		}
+
+		w.Close()
+		s.ln.Close()
+	}
+}
+
+/* 断开所有客户端的连接，模拟服务器因PING超时断开空闲的客户端 */
+func (s *fakeNats) dropClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func TestNatsWriterReconnect(t *testing.T) {
+	s := newFakeNats(t, false)
+	defer s.ln.Close()
+	w := NewNatsWriter(NatsConfig{Addr: s.ln.Addr().String()})
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s.dropClients()
+
+	w.Write([]byte("second\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("send after server dropped the connection: %v", err)
+	}
+	if msgs := s.messages(); len(msgs) != 2 || msgs[1] != "logs.unknown second" {
+		t.Errorf("messages = %q", msgs)
+	}
+}
+
+func TestNatsWriterDown(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := NewNatsWriter(NatsConfig{Addr: addr})
+	w.Write([]byte("lost\n"))
+	if err := w.Flush(); err == nil {
+		t.Error("expected error when server is down")
+	}
+	w.Close()
+}
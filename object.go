@@ -0,0 +1,173 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* 对象存储的上传接口，key为对象名，可适配S3、GCS等SDK的客户端 */
+type Uploader interface {
+	Upload(key string, data []byte) error
+}
+
+/* 以函数实现Uploader */
+type UploaderFunc func(key string, data []byte) error
+
+func (f UploaderFunc) Upload(key string, data []byte) error {
+	return f(key, data)
+}
+
+/* 按时间分段上传到对象存储的输出目标，用于没有本地日志采集的无服务器及批处理任务 */
+/* 每个时间段的日志合并为一个对象，对象名为 前缀+段开始时间(UTC)+".log"，如 logs/2018/06/01/12-00-00.log */
+/* 段内因Flush提前上传后，同一段之后的对象名加上序号以免覆盖，如 logs/2018/06/01/12-00-00-1.log */
+type ObjectWriter struct {
+	Retries int           /* 上传失败后的重试次数 */
+	Backoff time.Duration /* 首次重试前的等待时间，之后每次加倍 */
+
+	mu       sync.Mutex
+	uploader Uploader
+	prefix   string
+	period   time.Duration
+	start    time.Time /* 当前段的开始时间 */
+	last     time.Time /* 最近一次上传的段开始时间 */
+	part     int       /* 最近一次上传的段内序号 */
+	buf      []byte
+	err      error /* 最近一次上传最终失败的错误，在Flush或Close时返回 */
+	uploads  sync.WaitGroup
+	stop     chan struct{}
+	once     sync.Once
+}
+
+/* 创建按period分段上传的输出目标，默认失败后重试3次 */
+func NewObjectWriter(u Uploader, prefix string, period time.Duration) *ObjectWriter {
+	o := &ObjectWriter{
+		Retries:  3,
+		Backoff:  time.Second,
+		uploader: u,
+		prefix:   prefix,
+		period:   period,
+		stop:     make(chan struct{}),
+	}
+	go o.rotateLoop()
+	return o
+}
+
+func (o *ObjectWriter) rotateLoop() {
+	ticker := time.NewTicker(o.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			o.mu.Lock()
+			if !o.start.IsZero() && !now.Before(o.start.Add(o.period)) {
+				o.rotate()
+			}
+			o.mu.Unlock()
+		case <-o.stop:
+			return
+		}
+	}
+}
+
+func (o *ObjectWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.start.IsZero() && !now.Before(o.start.Add(o.period)) {
+		o.rotate()
+	}
+	if o.start.IsZero() {
+		o.start = now.UTC().Truncate(o.period)
+	}
+	o.buf = append(o.buf, p...)
+	return len(p), nil
+}
+
+/* 在后台上传当前段，调用方需持有o.mu */
+func (o *ObjectWriter) rotate() {
+	if len(o.buf) == 0 {
+		o.start = time.Time{}
+		return
+	}
+
+	if o.start.Equal(o.last) {
+		o.part++
+	} else {
+		o.last, o.part = o.start, 0
+	}
+	key := o.prefix + o.start.Format("2006/01/02/15-04-05")
+	if o.part > 0 {
+		key += "-" + strconv.Itoa(o.part)
+	}
+	key += ".log"
+	data := o.buf
+	o.buf, o.start = nil, time.Time{}
+
+	o.uploads.Add(1)
+	go func() {
+		defer o.uploads.Done()
+		if err := o.upload(key, data); err != nil {
+			o.mu.Lock()
+			o.err = err
+			o.mu.Unlock()
+		}
+	}()
+}
+
+func (o *ObjectWriter) upload(key string, data []byte) error {
+	backoff := o.Backoff
+	err := o.uploader.Upload(key, data)
+	for i := 0; err != nil && i < o.Retries; i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = o.uploader.Upload(key, data)
+	}
+	return err
+}
+
+/* 立即上传当前段并等待所有上传完成，返回此前上传最终失败的错误 */
+func (o *ObjectWriter) Flush() error {
+	o.mu.Lock()
+	o.rotate()
+	o.mu.Unlock()
+
+	o.uploads.Wait()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := o.err
+	o.err = nil
+	return err
+}
+
+/* 上传剩余的日志并停止定时分段 */
+func (o *ObjectWriter) Close() error {
+	o.once.Do(func() { close(o.stop) })
+	return o.Flush()
+}
@@ -0,0 +1,125 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* 记录上传对象的Uploader，前fail次上传失败 */
+type memUploader struct {
+	mu      sync.Mutex
+	objects map[string]string
+	calls   int
+	fail    int
+}
+
+func (u *memUploader) Upload(key string, data []byte) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.calls++
+	if u.calls <= u.fail {
+		return errors.New("503 slow down")
+	}
+	if u.objects == nil {
+		u.objects = make(map[string]string)
+	}
+	u.objects[key] += string(data)
+	return nil
+}
+
+func TestObjectWriter(t *testing.T) {
+	u := &memUploader{fail: 2}
+	o := NewObjectWriter(u, "logs/", time.Hour)
+	o.Backoff = time.Millisecond
+
+	l := NewLogger()
+	l.SetOutput(o)
+	l.Infoln("job started")
+	l.Infoln("job done")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(u.objects) != 1 || u.calls != 3 {
+		t.Fatalf("objects = %v after %d calls", u.objects, u.calls)
+	}
+	for key, data := range u.objects {
+		if !strings.HasPrefix(key, "logs/") || !strings.HasSuffix(key, "-00-00.log") {
+			t.Errorf("key = %q", key)
+		}
+		if !strings.Contains(data, "job started") || !strings.Contains(data, "job done") {
+			t.Errorf("data = %q", data)
+		}
+	}
+}
+
+func TestObjectWriterFailure(t *testing.T) {
+	u := &memUploader{fail: 10}
+	o := NewObjectWriter(u, "", time.Hour)
+	o.Retries, o.Backoff = 1, time.Millisecond
+	defer o.Close()
+
+	o.Write([]byte("lost\n"))
+	if err := o.Flush(); err == nil {
+		t.Error("expected upload error")
+	}
+	if u.calls != 2 {
+		t.Errorf("calls = %d, want 2", u.calls)
+	}
+}
+
+func TestObjectWriterFlushThenWrite(t *testing.T) {
+	var keys []string
+	var mu sync.Mutex
+	o := NewObjectWriter(UploaderFunc(func(key string, data []byte) error {
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+		return nil
+	}), "logs/", time.Hour)
+	defer o.Close()
+
+	o.Write([]byte("first\n"))
+	if err := o.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	o.Write([]byte("second\n"))
+	if err := o.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 || keys[0] == keys[1] {
+		t.Fatalf("keys = %v", keys)
+	}
+	if !strings.HasSuffix(keys[1], "-1.log") && !strings.HasSuffix(keys[1], "-00-00.log") {
+		t.Errorf("second key = %q", keys[1])
+	}
+}
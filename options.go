@@ -0,0 +1,146 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"time"
+)
+
+/* 构造Logger的选项，按传入顺序依次应用 */
+type Option func(l *Logger)
+
+/* 按选项构造Logger，未指定的部分与NewLogger相同 */
+/* 用法：zlog.New(zlog.WithLevel(zlog.INFO), zlog.WithOutput(f), zlog.WithFormat(zlog.JSON)) */
+func New(opts ...Option) *Logger {
+	l := NewLogger()
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+/* 设置全局日志级别，同SetLevel */
+func WithLevel(level uint8) Option {
+	return func(l *Logger) {
+		l.SetLevel(level)
+	}
+}
+
+/* 设置标志的日志级别，同SetTagLevel */
+func WithTagLevel(level uint8, tags ...string) Option {
+	return func(l *Logger) {
+		l.SetTagLevel(level, tags...)
+	}
+}
+
+/* 设置源文件的日志级别，同SetFileLevel */
+func WithFileLevel(level uint8, files ...string) Option {
+	return func(l *Logger) {
+		l.SetFileLevel(level, files...)
+	}
+}
+
+/* 设置输出目标，同SetOutput */
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetOutput(w)
+	}
+}
+
+/* 设置ERROR及以上级别日志的输出目标，同SetErrorOutput */
+func WithErrorOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetErrorOutput(w)
+	}
+}
+
+/* ERROR及以上级别输出到标准错误，其他级别输出到标准输出，同SplitOutput */
+func WithSplitOutput() Option {
+	return func(l *Logger) {
+		l.SplitOutput()
+	}
+}
+
+/* 设置日志格式，同SetFormatter */
+func WithFormat(f Formatter) Option {
+	return func(l *Logger) {
+		l.SetFormatter(f)
+	}
+}
+
+/* 解析调用者时额外跳过n层，同AddCallerSkip */
+func WithCallerSkip(n int) Option {
+	return func(l *Logger) {
+		l.AddCallerSkip(n)
+	}
+}
+
+/* 设置是否解析调用者，同SetCaller */
+func WithCaller(on bool) Option {
+	return func(l *Logger) {
+		l.SetCaller(on)
+	}
+}
+
+/* 设置采样，同SetSampling */
+func WithSampling(first, thereafter uint64, tick time.Duration) Option {
+	return func(l *Logger) {
+		l.SetSampling(first, thereafter, tick)
+	}
+}
+
+/* 添加自定义过滤规则，同AddFilter */
+func WithFilters(filters ...Filter) Option {
+	return func(l *Logger) {
+		l.AddFilter(filters...)
+	}
+}
+
+/* 对指定键名的值脱敏，同RedactKeys */
+func WithRedactKeys(keys ...string) Option {
+	return func(l *Logger) {
+		l.RedactKeys(keys...)
+	}
+}
+
+/* 设置Fatal系列方法退出进程时的退出码，同SetExitCode */
+func WithExitCode(code int) Option {
+	return func(l *Logger) {
+		l.SetExitCode(code)
+	}
+}
+
+/* 设置Assertf失败时是否panic，同SetAssertPanic */
+func WithAssertPanic(on bool) Option {
+	return func(l *Logger) {
+		l.SetAssertPanic(on)
+	}
+}
+
+/* 添加中间件，同Use */
+func WithMiddleware(mws ...Middleware) Option {
+	return func(l *Logger) {
+		l.Use(mws...)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var out, errOut bytes.Buffer
+	l := New(
+		WithLevel(INFO),
+		WithTagLevel(DEBUG, "db"),
+		WithOutput(&out),
+		WithErrorOutput(&errOut),
+		WithFormat(JSON),
+		WithRedactKeys("token"),
+	)
+
+	if l.Enabled(DEBUG, "x") || !l.Enabled(INFO, "x") || !l.Enabled(DEBUG, "db") {
+		t.Error("unexpected levels")
+	}
+	if _, ok := l.Formatter().(*JSONFormatter); !ok {
+		t.Errorf("formatter = %T", l.Formatter())
+	}
+
+	l.Debug("hidden")
+	l.Info("started", F("token", "secret"))
+	l.Error("failed")
+	if s := out.String(); strings.Contains(s, "hidden") || !strings.Contains(s, `"message":"started"`) || !strings.Contains(s, `"token":"***"`) {
+		t.Errorf("unexpected output: %q", s)
+	}
+	if s := errOut.String(); !strings.Contains(s, `"message":"failed"`) {
+		t.Errorf("unexpected error output: %q", s)
+	}
+
+	if d := New(); !d.Enabled(VERBOSE, "x") {
+		t.Error("New() without options should match NewLogger")
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	var out bytes.Buffer
+	l := New(WithOutput(&out), WithFormat(&JSONFormatter{}), WithCaller(false))
+	l.Info("no caller")
+	if s := out.String(); strings.Contains(s, "options_test.go") || strings.Contains(s, "TestWithCaller") {
+		t.Errorf("caller resolved: %q", s)
+	}
+
+	out.Reset()
+	l.SetCaller(true)
+	l.Info("caller")
+	if s := out.String(); !strings.Contains(s, "TestWithCaller") {
+		t.Errorf("caller missing: %q", s)
+	}
+}
@@ -0,0 +1,162 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/* 布局中的一段，verb为空时为原样输出的文本 */
+type patternPart struct {
+	verb string
+	arg  string /* 如%time{15:04:05}%中的时间格式 */
+}
+
+/* 由布局字符串描述的格式，如"%time% [%level%] %tag% %caller% - %msg% %fields%" */
+/* 支持%time%(可写为%time{15:04:05.000}%指定格式)、%level%、%tag%、%fulltag%、%func%、%caller%(标志.函数)、 */
+/* %file%、%line%、%msg%、%fields%、%goroutine%，%%输出百分号 */
+type PatternFormatter struct {
+	parts     []patternPart
+	goroutine bool
+}
+
+var patternVerbs = map[string]bool{
+	"time": true, "level": true, "tag": true, "fulltag": true, "func": true, "caller": true,
+	"file": true, "line": true, "msg": true, "fields": true, "goroutine": true,
+}
+
+/* 解析布局字符串，可用于从配置文件读取日志格式 */
+func NewPatternFormatter(layout string) (*PatternFormatter, error) {
+	f := new(PatternFormatter)
+	for layout != "" {
+		i := strings.IndexByte(layout, '%')
+		if i < 0 {
+			f.parts = append(f.parts, patternPart{arg: layout})
+			break
+		}
+		if i > 0 {
+			f.parts = append(f.parts, patternPart{arg: layout[:i]})
+		}
+
+		layout = layout[i+1:]
+		if strings.HasPrefix(layout, "%") {
+			f.parts = append(f.parts, patternPart{arg: "%"})
+			layout = layout[1:]
+			continue
+		}
+
+		j := strings.IndexAny(layout, "{%")
+		if j < 0 {
+			return nil, errors.New("zlog: unterminated verb in layout")
+		}
+		part := patternPart{verb: layout[:j]}
+		layout = layout[j:]
+		if layout[0] == '{' {
+			k := strings.IndexByte(layout, '}')
+			if k < 0 || !strings.HasPrefix(layout[k+1:], "%") {
+				return nil, errors.New("zlog: unterminated verb in layout")
+			}
+			part.arg, layout = layout[1:k], layout[k+1:]
+		}
+		layout = layout[1:]
+
+		if !patternVerbs[part.verb] || part.arg != "" && part.verb != "time" {
+			return nil, fmt.Errorf("zlog: unknown layout verb %q", part.verb)
+		}
+		if part.verb == "time" && part.arg == "" {
+			part.arg = "2006/01/02 15:04:05"
+		}
+		f.goroutine = f.goroutine || part.verb == "goroutine"
+		f.parts = append(f.parts, part)
+	}
+	return f, nil
+}
+
+/* 与NewPatternFormatter相同，布局有误时panic，用于布局固定的场合 */
+func MustPatternFormatter(layout string) *PatternFormatter {
+	f, err := NewPatternFormatter(layout)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f *PatternFormatter) wantsGoroutine() bool {
+	return f.goroutine
+}
+
+func (f *PatternFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	var scratch [32]byte
+	start := buf.Len()
+	for _, part := range f.parts {
+		switch part.verb {
+		case "":
+			buf.WriteString(part.arg)
+		case "time":
+			buf.Write(e.Time.AppendFormat(scratch[:0], part.arg))
+		case "level":
+			buf.WriteString(LogLevelNames[e.Level])
+		case "tag":
+			buf.WriteString(lastPath(e.Tag))
+		case "fulltag":
+			buf.WriteString(e.Tag)
+		case "func":
+			buf.WriteString(e.Func)
+		case "caller":
+			buf.WriteString(lastPath(e.Tag))
+			buf.WriteByte('.')
+			buf.WriteString(e.Func)
+		case "file":
+			if e.File != "" {
+				buf.WriteString(filepath.Base(e.File))
+			}
+		case "line":
+			buf.Write(strconv.AppendInt(scratch[:0], int64(e.Line), 10))
+		case "msg":
+			buf.WriteString(e.Message)
+		case "fields":
+			/* 去掉writeFields开头的空格，分隔由布局决定 */
+			if at := buf.Len(); len(e.Fields) > 0 {
+				writeFields(buf, e.Fields)
+				b := buf.Bytes()
+				copy(b[at:], b[at+1:])
+				buf.Truncate(len(b) - 1)
+			}
+		case "goroutine":
+			buf.Write(strconv.AppendUint(scratch[:0], e.Goroutine, 10))
+		}
+	}
+
+	/* 没有字段等情况下去掉结尾多余的空格 */
+	b := buf.Bytes()
+	end := len(b)
+	for end > start && (b[end-1] == ' ' || b[end-1] == '\t') {
+		end--
+	}
+	buf.Truncate(end)
+}
@@ -0,0 +1,72 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPatternFormatter(t *testing.T) {
+	e := newEntry(INFO, "github/com/fpay/fpay/p2p", "Dial", "connected", F("peer", "10.0.0.2"), F("n", 3))
+	e.Time = time.Date(2018, 6, 1, 15, 4, 5, 0, time.UTC)
+	e.File, e.Line = "/src/fpay/p2p/dial.go", 42
+
+	for layout, want := range map[string]string{
+		"%time% [%level%] %tag% %caller% - %msg% %fields%": "2018/06/01 15:04:05 [INFO] p2p p2p.Dial - connected peer=10.0.0.2 n=3",
+		"%time{15:04}% %file%:%line% 100%% %msg%":          "15:04 dial.go:42 100% connected",
+		"%fulltag%.%func%": "github/com/fpay/fpay/p2p.Dial",
+	} {
+		f, err := NewPatternFormatter(layout)
+		if err != nil {
+			t.Fatalf("%q: %v", layout, err)
+		}
+
+		var buf bytes.Buffer
+		f.Format(&buf, e)
+		if buf.String() != want {
+			t.Errorf("%q:\ngot  %q\nwant %q", layout, buf.String(), want)
+		}
+	}
+
+	var buf bytes.Buffer
+	MustPatternFormatter("%msg% %fields%").Format(&buf, newEntry(INFO, "", "", "bare"))
+	if buf.String() != "bare" {
+		t.Errorf("empty fields: %q", buf.String())
+	}
+
+	buf.Reset()
+	MustPatternFormatter("[%file%] %msg%").Format(&buf, newEntry(INFO, "", "", "no file"))
+	if buf.String() != "[] no file" {
+		t.Errorf("empty file: %q", buf.String())
+	}
+
+	for _, bad := range []string{"%nope%", "%msg", "%level{x}%", "%time{15:04%"} {
+		if _, err := NewPatternFormatter(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
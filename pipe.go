@@ -0,0 +1,105 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+/* 写入命名管道的输出目标，用于向基于管道的采集程序输出日志 */
+/* Windows上name为管道名(如zlog)或完整路径(如\\.\pipe\zlog)，其他系统上为FIFO的路径 */
+/* 管道断开(如采集程序重启)后在下一次写入时重新打开 */
+type PipeWriter struct {
+	mu      sync.Mutex
+	path    string
+	timeout time.Duration /* 管道忙或没有读取端时等待的最长时间 */
+	f       *os.File
+}
+
+/* 打开命名管道，管道忙或没有读取端时最多等待timeout，timeout为0时默认5秒 */
+func NewPipeWriter(name string, timeout time.Duration) (*PipeWriter, error) {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	p := &PipeWriter{path: pipePath(name), timeout: timeout}
+	if err := p.open(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/* 调用方需持有p.mu */
+func (p *PipeWriter) open() error {
+	deadline := time.Now().Add(p.timeout)
+	for {
+		f, err := openPipe(p.path)
+		if err == nil {
+			p.f = f
+			return nil
+		}
+		if !pipeBusy(err) || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (p *PipeWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.f == nil {
+		if err := p.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := p.f.Write(b)
+	if err == nil {
+		return n, nil
+	}
+
+	/* 读取端已关闭，重新打开后重写剩余的内容 */
+	p.f.Close()
+	p.f = nil
+	if oerr := p.open(); oerr != nil {
+		return n, err
+	}
+	m, err := p.f.Write(b[n:])
+	return n + m, err
+}
+
+func (p *PipeWriter) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.f == nil {
+		return nil
+	}
+	err := p.f.Close()
+	p.f = nil
+	return err
+}
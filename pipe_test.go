@@ -0,0 +1,97 @@
+//go:build linux || darwin
+
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPipeWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zlog.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skip(err)
+	}
+
+	lines := make(chan string, 4)
+	go func() {
+		f, err := os.Open(path)
+		if err != nil {
+			close(lines)
+			return
+		}
+		defer f.Close()
+
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+		close(lines)
+	}()
+
+	p, err := NewPipeWriter(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLogger()
+	l.SetOutput(p)
+	l.Infoln("through the pipe")
+	p.Close()
+
+	if line := <-lines; !strings.HasSuffix(line, "] through the pipe") {
+		t.Errorf("read %q", line)
+	}
+}
+
+func TestPipeWriterNoReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zlog.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skip(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewPipeWriter(path, 100*time.Millisecond)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, syscall.ENXIO) {
+			t.Errorf("err = %v, want ENXIO", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("NewPipeWriter blocked without a reader")
+	}
+}
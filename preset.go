@@ -0,0 +1,49 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"os"
+	"time"
+)
+
+/* 适合本地开发的Logger：DEBUG级别、着色文本，调用者输出源文件及行号，输出到标准错误，Assertf失败时panic */
+func NewDevelopment() *Logger {
+	l := NewLogger()
+	l.SetLevel(DEBUG)
+	l.SetFormatter(&TextFormatter{Caller: CallerFile, Multiline: MultilineIndent})
+	l.SetOutput(os.Stderr)
+	l.SetAssertPanic(true)
+	return l
+}
+
+/* 适合生产环境的Logger：INFO级别、JSON格式，ERROR及以上级别输出到标准错误、其他输出到标准输出 */
+/* 每个调用处每秒输出前100条，之后每100条输出一条 */
+func NewProduction() *Logger {
+	l := NewLogger()
+	l.SetLevel(INFO)
+	l.SetFormatter(&JSONFormatter{})
+	l.SplitOutput()
+	l.SetSampling(100, 100, time.Second)
+	return l
+}
@@ -0,0 +1,71 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"strings"
+)
+
+/* 与标准库log同名的函数，均以INFO级别输出，便于以zlog替换log的导入 */
+
+func (l *Logger) logp(skip int, level uint8, v ...interface{}) {
+	if !l.mayLog(level) {
+		return
+	}
+
+	c := l.caller(2, skip)
+	if l.wants(level, c.pkg, c.file) {
+		l.output(c.entry(level, strings.TrimSuffix(fmt.Sprint(resolve(v)...), "\n")))
+	}
+}
+
+/* 以INFO级别输出，参数按fmt.Sprint拼接 */
+func (l *Logger) Print(v ...interface{}) {
+	l.logp(0, INFO, v...)
+}
+
+/* 同Infof */
+func (l *Logger) Printf(format string, v ...interface{}) {
+	l.logf(0, INFO, format, v...)
+}
+
+/* 同Infoln */
+func (l *Logger) Println(v ...interface{}) {
+	l.logln(0, INFO, v...)
+}
+
+/* 以INFO级别输出，参数按fmt.Sprint拼接 */
+func Print(v ...interface{}) {
+	std.logp(0, INFO, v...)
+}
+
+/* 同Infof */
+func Printf(format string, v ...interface{}) {
+	std.logf(0, INFO, format, v...)
+}
+
+/* 同Infoln */
+func Println(v ...interface{}) {
+	std.logln(0, INFO, v...)
+}
@@ -0,0 +1,64 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+/* 标准库*log.Logger与*Logger共有的方法 */
+type stdPrinter interface {
+	Print(v ...interface{})
+	Printf(format string, v ...interface{})
+	Println(v ...interface{})
+}
+
+var _ = []stdPrinter{log.Default(), NewLogger()}
+
+func TestPrint(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+
+	l.Print("a", "b", 1, 2)
+	l.Printf("user %d", 7)
+	l.Println("a", "b")
+	l.Print("trailing\n")
+
+	want := []string{"] ab1 2", "] user 7", "] a b", "] trailing"}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected output: %q", lines)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line[20:], "[INFO][zlog: TestPrint]") || !strings.HasSuffix(line, want[i]) {
+			t.Errorf("line %d = %q, want INFO ending with %q", i, line, want[i])
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultProgressInterval = 5 * time.Second /* Progress输出进度的默认最小间隔 */
+
+/* 长时间循环的进度日志，按间隔输出如"processed 10000/250000 (4%) items, ETA 3m0s"，可在多个goroutine中使用 */
+type Progress struct {
+	logger   *Logger
+	c        callerInfo /* 创建Progress的调用者 */
+	level    uint8
+	unit     string
+	total    int64
+	interval time.Duration
+	start    time.Time
+	done     int64 /* 已完成的数量，原子读写 */
+	next     int64 /* 下次允许输出的时间，Unix纳秒，原子读写 */
+}
+
+/* 创建进度日志，unit为计数的单位，如"items"，total未知时为0，interval为0时使用DefaultProgressInterval */
+/* 用法：p := zlog.NewProgress(INFO, "rows", total, 0); for ... { p.Add(1) }; p.Done() */
+func (l *Logger) NewProgress(level uint8, unit string, total int64, interval time.Duration) *Progress {
+	return l.newProgress(level, unit, total, interval)
+}
+
+func (l *Logger) newProgress(level uint8, unit string, total int64, interval time.Duration) *Progress {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	now := time.Now()
+	return &Progress{
+		logger:   l,
+		c:        l.caller(2, 0),
+		level:    level,
+		unit:     unit,
+		total:    total,
+		interval: interval,
+		start:    now,
+		next:     now.Add(interval).UnixNano(),
+	}
+}
+
+/* 以默认日志记录器创建进度日志 */
+func NewProgress(level uint8, unit string, total int64, interval time.Duration) *Progress {
+	return std.newProgress(level, unit, total, interval)
+}
+
+/* 增加已完成的数量，距上次输出超过间隔时输出进度 */
+func (p *Progress) Add(n int64) {
+	p.report(atomic.AddInt64(&p.done, n))
+}
+
+/* 设置已完成的数量，距上次输出超过间隔时输出进度 */
+func (p *Progress) Set(done int64) {
+	atomic.StoreInt64(&p.done, done)
+	p.report(done)
+}
+
+func (p *Progress) report(done int64) {
+	now := time.Now()
+	next := atomic.LoadInt64(&p.next)
+	if now.UnixNano() < next || !atomic.CompareAndSwapInt64(&p.next, next, now.Add(p.interval).UnixNano()) {
+		return
+	}
+
+	msg := p.message(done)
+	if p.total > 0 && done > 0 && done < p.total {
+		elapsed := now.Sub(p.start)
+		eta := time.Duration(float64(elapsed) * float64(p.total-done) / float64(done))
+		msg += ", ETA " + eta.Round(time.Second).String()
+	}
+	p.log(msg)
+}
+
+/* 输出最终的完成数量及总耗时 */
+func (p *Progress) Done() {
+	msg := p.message(atomic.LoadInt64(&p.done))
+	p.log(msg + " in " + time.Since(p.start).Round(time.Millisecond).String())
+}
+
+func (p *Progress) message(done int64) string {
+	msg := "processed " + strconv.FormatInt(done, 10)
+	if p.total > 0 {
+		msg += "/" + strconv.FormatInt(p.total, 10) + " (" + strconv.FormatInt(done*100/p.total, 10) + "%)"
+	}
+	return msg + " " + p.unit
+}
+
+func (p *Progress) log(msg string) {
+	if p.logger.wants(p.level, p.c.pkg, p.c.file) {
+		p.logger.output(p.c.entry(p.level, msg))
+	}
+}
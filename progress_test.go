@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	p := l.NewProgress(INFO, "items", 250000, time.Hour)
+	p.Add(10000)
+	if buf.Len() != 0 {
+		t.Fatalf("logged before interval: %q", buf.String())
+	}
+
+	p.start = time.Now().Add(-12 * time.Second)
+	p.next = 0
+	p.Add(0)
+	if out := buf.String(); !strings.Contains(out, "[zlog: TestProgress] processed 10000/250000 (4%) items, ETA 4m48s\n") {
+		t.Errorf("progress: %q", out)
+	}
+
+	buf.Reset()
+	p.Set(250000)
+	p.Done()
+	if out := buf.String(); !strings.Contains(out, "] processed 250000/250000 (100%) items in 12") {
+		t.Errorf("done: %q", out)
+	}
+
+	buf.Reset()
+	q := l.NewProgress(INFO, "rows", 0, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	q.Add(7)
+	if out := buf.String(); !strings.Contains(out, "] processed 7 rows\n") {
+		t.Errorf("unknown total: %q", out)
+	}
+}
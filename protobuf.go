@@ -0,0 +1,136 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+/* protobuf线路类型 */
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+)
+
+/* protobuf格式，每条记录为varint长度前缀加一个zlog.proto中的LogEntry，可用protodelim等读取 */
+type ProtobufFormatter struct{}
+
+func (f *ProtobufFormatter) binary() {}
+
+func (f *ProtobufFormatter) Format(buf *bytes.Buffer, e *Entry) {
+	msg := make([]byte, 0, 256)
+	msg = pbUint(msg, 1, uint64(e.Level))
+	msg = pbUint(msg, 2, uint64(e.Time.UnixNano()))
+	msg = pbString(msg, 3, e.Tag)
+	msg = pbString(msg, 4, e.Func)
+	msg = pbString(msg, 5, e.File)
+	msg = pbUint(msg, 6, uint64(int64(e.Line)))
+	msg = pbString(msg, 7, e.Message)
+
+	for _, field := range e.Fields {
+		var fb []byte
+		fb = pbString(fb, 1, field.Key)
+		fb = pbFieldValue(fb, field.Value)
+		msg = pbBytesField(msg, 8, fb)
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+	buf.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(msg)))])
+	buf.Write(msg)
+}
+
+func pbFieldValue(b []byte, v interface{}) []byte {
+	switch val := jsonValue(v).(type) {
+	case nil:
+		return b
+	case bool:
+		n := uint64(0)
+		if val {
+			n = 1
+		}
+		return binary.AppendUvarint(pbKey(b, 6, pbVarint), n)
+	case int:
+		return pbSint(b, 3, int64(val))
+	case int8:
+		return pbSint(b, 3, int64(val))
+	case int16:
+		return pbSint(b, 3, int64(val))
+	case int32:
+		return pbSint(b, 3, int64(val))
+	case int64:
+		return pbSint(b, 3, val)
+	case uint:
+		return binary.AppendUvarint(pbKey(b, 4, pbVarint), uint64(val))
+	case uint8:
+		return binary.AppendUvarint(pbKey(b, 4, pbVarint), uint64(val))
+	case uint16:
+		return binary.AppendUvarint(pbKey(b, 4, pbVarint), uint64(val))
+	case uint32:
+		return binary.AppendUvarint(pbKey(b, 4, pbVarint), uint64(val))
+	case uint64:
+		return binary.AppendUvarint(pbKey(b, 4, pbVarint), val)
+	case float32:
+		return binary.LittleEndian.AppendUint64(pbKey(b, 5, pbFixed64), math.Float64bits(float64(val)))
+	case float64:
+		return binary.LittleEndian.AppendUint64(pbKey(b, 5, pbFixed64), math.Float64bits(val))
+	case string:
+		return pbBytesField(b, 2, []byte(val))
+	case []byte:
+		return pbBytesField(b, 7, val)
+	default:
+		return pbBytesField(b, 2, []byte(fmt.Sprint(val)))
+	}
+}
+
+func pbKey(b []byte, num, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(num<<3|wire))
+}
+
+/* proto3省略零值 */
+func pbUint(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(pbKey(b, num, pbVarint), v)
+}
+
+/* sint64使用zigzag编码，oneof中的零值也需写出 */
+func pbSint(b []byte, num int, v int64) []byte {
+	return binary.AppendUvarint(pbKey(b, num, pbVarint), uint64(v<<1^v>>63))
+}
+
+func pbString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return pbBytesField(b, num, []byte(s))
+}
+
+func pbBytesField(b []byte, num int, v []byte) []byte {
+	b = binary.AppendUvarint(pbKey(b, num, pbBytes), uint64(len(v)))
+	return append(b, v...)
+}
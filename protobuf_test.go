@@ -0,0 +1,50 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestProtobufFormatter(t *testing.T) {
+	e := newEntry(WARNING, "p2p", "Dial", "hi", F("n", -2), F("u", uint8(3)), F("ok", true), F("nil", nil))
+	e.Time = time.Unix(0, 300)
+
+	var buf bytes.Buffer
+	(&ProtobufFormatter{}).Format(&buf, e)
+
+	want := "31" + /* 长度49 */
+		"0828" + "10ac02" + "1a03703270" + "2204" + "4469616c" + "3a026869" +
+		"4205" + "0a016e" + "1803" +
+		"4205" + "0a0175" + "2003" +
+		"4206" + "0a026f6b" + "3001" +
+		"4205" + "0a036e696c"
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/* 保存最近若干条日志的环形缓冲，不受级别及内容过滤影响 */
+type ringBuffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int /* 下一条日志的写入位置 */
+	count   int /* 已保存的日志条数 */
+}
+
+/* 实时订阅日志的接收方 */
+type subscriber struct {
+	ch chan Entry
+}
+
+var (
+	recent       ringBuffer
+	recentActive int32 /* 是否启用最近日志缓冲，原子读写 */
+
+	subMu       sync.Mutex
+	subscribers = map[*subscriber]struct{}{}
+	subCount    int32 /* 订阅者数量，原子读写 */
+)
+
+/* 是否需要记录未达到输出级别的日志 */
+func recording() bool {
+	return atomic.LoadInt32(&recentActive) != 0 || atomic.LoadInt32(&subCount) != 0
+}
+
+/* 订阅之后产生的所有日志，包括未达到输出级别的日志，接收不及时的日志将被丢弃 */
+/* 返回接收日志的通道及取消订阅的函数 */
+func subscribe(size int) (<-chan Entry, func()) {
+	s := &subscriber{ch: make(chan Entry, size)}
+	subMu.Lock()
+	subscribers[s] = struct{}{}
+	atomic.StoreInt32(&subCount, int32(len(subscribers)))
+	subMu.Unlock()
+
+	return s.ch, func() {
+		subMu.Lock()
+		delete(subscribers, s)
+		atomic.StoreInt32(&subCount, int32(len(subscribers)))
+		subMu.Unlock()
+	}
+}
+
+func publish(entry Entry) {
+	subMu.Lock()
+	for s := range subscribers {
+		select {
+		case s.ch <- entry:
+		default:
+		}
+	}
+	subMu.Unlock()
+}
+
+/* 记录一条日志到最近日志缓冲，并发送给订阅者 */
+func record(e *Entry) {
+	if !recording() {
+		return
+	}
+
+	entry := *e
+	entry.Fields = append([]Field(nil), e.Fields...)
+	if atomic.LoadInt32(&subCount) != 0 {
+		publish(entry)
+	}
+
+	if atomic.LoadInt32(&recentActive) == 0 {
+		return
+	}
+
+	recent.mu.Lock()
+	if len(recent.entries) > 0 {
+		recent.entries[recent.next] = entry
+		recent.next = (recent.next + 1) % len(recent.entries)
+		if recent.count < len(recent.entries) {
+			recent.count++
+		}
+	}
+	recent.mu.Unlock()
+}
+
+/* 保存所有Logger最近size条日志，包括未达到输出级别的日志，size不大于0时关闭并清空 */
+/* 出错时可通过Recent()取得之前的DEBUG上下文；启用后未输出的日志也需格式化，会增加开销 */
+func SetRecentSize(size int) {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+
+	if size <= 0 {
+		atomic.StoreInt32(&recentActive, 0)
+		recent.entries, recent.next, recent.count = nil, 0, 0
+		return
+	}
+
+	old := recentLocked()
+	if len(old) > size {
+		old = old[len(old)-size:]
+	}
+	recent.entries = make([]Entry, size)
+	recent.count = copy(recent.entries, old)
+	recent.next = recent.count % size
+	atomic.StoreInt32(&recentActive, 1)
+}
+
+/* 调用方需持有recent.mu */
+func recentLocked() []Entry {
+	res := make([]Entry, 0, recent.count)
+	start := recent.next - recent.count
+	if start < 0 {
+		start += len(recent.entries)
+	}
+	for i := 0; i < recent.count; i++ {
+		res = append(res, recent.entries[(start+i)%len(recent.entries)])
+	}
+	return res
+}
+
+/* 返回最近的日志，由旧到新排列 */
+func Recent() []Entry {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	return recentLocked()
+}
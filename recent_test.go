@@ -0,0 +1,58 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecent(t *testing.T) {
+	SetRecentSize(3)
+	defer SetRecentSize(0)
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(ERROR)
+
+	l.Debugln("one")
+	l.Debugln("two")
+	l.Infoln("three")
+	l.Errorln("four")
+
+	entries := Recent()
+	if len(entries) != 3 || entries[0].Message != "two" || entries[1].Message != "three" || entries[2].Message != "four" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("two")) || !bytes.Contains(buf.Bytes(), []byte("four")) {
+		t.Fatalf("output filtering changed: %q", buf.String())
+	}
+
+	SetRecentSize(2)
+	if entries := Recent(); len(entries) != 2 || entries[0].Message != "three" {
+		t.Fatalf("resize lost newest entries: %+v", entries)
+	}
+}
@@ -0,0 +1,121 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const redactedMask = "***" /* 敏感内容的替换文本 */
+
+/* 敏感信息脱敏规则 */
+type redactor struct {
+	keys     []string         /* 需要脱敏的键名，不区分大小写 */
+	keyValue *regexp.Regexp   /* 由keys生成的匹配key=value、key: value的正则 */
+	patterns []*regexp.Regexp /* 需要整体替换的内容，如银行卡号 */
+}
+
+func (r *redactor) empty() bool {
+	return r.keyValue == nil && len(r.patterns) == 0
+}
+
+func (r *redactor) redactKey(key string) bool {
+	for _, k := range r.keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) redact(msg string) string {
+	if r.keyValue != nil {
+		msg = r.keyValue.ReplaceAllString(msg, "${1}"+redactedMask)
+	}
+
+	for _, re := range r.patterns {
+		msg = re.ReplaceAllString(msg, redactedMask)
+	}
+	return msg
+}
+
+/* 对指定键名的值脱敏，如password=123456将输出为password=*** */
+func (l *Logger) RedactKeys(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.redactor.keys = append(l.redactor.keys, keys...)
+	quoted := make([]string, len(l.redactor.keys))
+	for i, key := range l.redactor.keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	l.redactor.keyValue = regexp.MustCompile(`(?i)(\b(?:` + strings.Join(quoted, "|") + `)"?\s*[=:]\s*)("[^"]*"|(?:(?:bearer|basic)\s+)?[^\s,;&]+)`)
+}
+
+/* 将匹配任一正则的内容替换为***，如`\b\d{16}\b` */
+func (l *Logger) RedactPatterns(exprs ...string) error {
+	res, err := compileAll(exprs)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.redactor.patterns = append(l.redactor.patterns, res...)
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Logger) redact(e *Entry) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.redactor.empty() {
+		return
+	}
+
+	e.Message = l.redactor.redact(e.Message)
+	if len(e.Fields) == 0 {
+		return
+	}
+
+	/* 字段可能来自调用方的可变参数，不能原地修改 */
+	fields := append([]Field(nil), e.Fields...)
+	for i, field := range fields {
+		switch v := field.Value.(type) {
+		case string:
+			fields[i].Value = l.redactor.redact(v)
+		case error, fmt.Stringer:
+			/* 按输出时的文本脱敏，内容被替换时以字符串代替原值 */
+			s := fmt.Sprint(v)
+			if masked := l.redactor.redact(s); masked != s {
+				fields[i].Value = masked
+			}
+		}
+		if l.redactor.redactKey(field.Key) {
+			fields[i].Value = redactedMask
+		}
+	}
+	e.Fields = fields
+}
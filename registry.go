@@ -0,0 +1,137 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+/* GetLogger创建的命名Logger */
+var registry struct {
+	sync.Mutex
+	loggers map[string]*Logger
+}
+
+/* 返回名为name的Logger，首次调用时创建，之后返回同一个，name为空时返回默认日志记录器 */
+/* 名称以.分隔层级，如fpay.p2p.gossip的上级为fpay.p2p，顶级名称的上级为默认日志记录器，缺少的上级一并创建 */
+/* 新建的Logger是上级的副本，未通过SetLevel单独设置级别时沿用最近的设置了级别的上级的级别 */
+/* 新建的Logger没有自己的输出目标，日志写入各级上级的输出目标，可通过SetOutput及SetPropagate隔离 */
+/* 命名Logger的日志默认以名称作为标志，Tagged等显式指定的标志不变，便于框架在启动时通过Loggers统一配置 */
+func GetLogger(name string) *Logger {
+	registry.Lock()
+	defer registry.Unlock()
+	return getLogger(name)
+}
+
+/* 调用方需持有registry的锁 */
+func getLogger(name string) *Logger {
+	if name == "" {
+		return std
+	}
+
+	if l, ok := registry.loggers[name]; ok {
+		return l
+	}
+
+	if registry.loggers == nil {
+		registry.loggers = make(map[string]*Logger)
+	}
+	parent := getLogger(parentName(name))
+	l := parent.Clone()
+	l.name, l.parent, l.propagate = name, parent, true
+	l.out, l.errOut, l.routes, l.tagRoutes = io.Discard, nil, nil, nil
+	registry.loggers[name] = l
+	return l
+}
+
+/* 上级Logger的名称，顶级名称的上级为默认日志记录器，即空名称 */
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+/* 将未单独设置级别的命名Logger的级别更新为上级的级别 */
+func inheritLevels() {
+	registry.Lock()
+	defer registry.Unlock()
+
+	/* 上级的名称是下级的前缀，按名称排序后上级总在下级之前更新 */
+	names := make([]string, 0, len(registry.loggers))
+	for name := range registry.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l := registry.loggers[name]
+		l.mu.Lock()
+		if !l.levelSet {
+			atomic.StoreUint32(&l.level, atomic.LoadUint32(&l.parent.level))
+			l.updateMinLevel()
+		}
+		l.mu.Unlock()
+	}
+}
+
+/* 取消单独设置的级别，恢复继承上级的级别，只对GetLogger创建的Logger有效 */
+func (l *Logger) InheritLevel() {
+	if l.parent == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.levelSet = false
+	l.mu.Unlock()
+	inheritLevels()
+}
+
+/* 返回所有GetLogger创建的Logger，名称 -> Logger */
+func Loggers() map[string]*Logger {
+	registry.Lock()
+	defer registry.Unlock()
+
+	loggers := make(map[string]*Logger, len(registry.loggers))
+	for name, l := range registry.loggers {
+		loggers[name] = l
+	}
+	return loggers
+}
+
+/* 返回GetLogger创建时的名称，其副本沿用该名称，其他Logger为空 */
+func (l *Logger) Name() string {
+	return l.name
+}
+
+/* 设置日志是否同时写入上级的输出目标，默认写入，关闭后只写入自己的输出目标，如单独的文件 */
+/* 写入上级时使用上级的格式，不经过上级的级别过滤，只对GetLogger创建的Logger有效 */
+func (l *Logger) SetPropagate(on bool) {
+	l.mu.Lock()
+	l.propagate = on && l.parent != nil
+	l.mu.Unlock()
+}
@@ -0,0 +1,181 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/* 测试期间使用空的注册表，结束后恢复，以便-count多次运行 */
+func resetRegistry(t *testing.T) {
+	registry.Lock()
+	saved := registry.loggers
+	registry.loggers = nil
+	registry.Unlock()
+
+	t.Cleanup(func() {
+		registry.Lock()
+		registry.loggers = saved
+		registry.Unlock()
+	})
+}
+
+func TestGetLogger(t *testing.T) {
+	resetRegistry(t)
+	if GetLogger("") != std {
+		t.Error("empty name should return the default logger")
+	}
+
+	l := GetLogger("registry-test")
+	if GetLogger("registry-test") != l || l.Name() != "registry-test" {
+		t.Fatal("GetLogger should memoize by name")
+	}
+	if Loggers()["registry-test"] != l {
+		t.Error("Loggers missing registry-test")
+	}
+
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	l.SetPropagate(false)
+	l.SetLevel(WARNING)
+	l.Info("hidden")
+	l.Warning("shown")
+	if s := buf.String(); strings.Contains(s, "hidden") || !strings.Contains(s, "[registry-test: TestGetLogger] shown") {
+		t.Errorf("unexpected output: %q", s)
+	}
+	if !std.Enabled(INFO, "x") {
+		t.Error("configuring a named logger changed the default logger")
+	}
+}
+
+func TestLoggerHierarchy(t *testing.T) {
+	resetRegistry(t)
+	root := GetLogger("hier")
+	root.SetLevel(WARNING)
+	gossip := GetLogger("hier.p2p.gossip")
+	p2p := Loggers()["hier.p2p"]
+	if p2p == nil || gossip.parent != p2p || p2p.parent != root || root.parent != std {
+		t.Fatal("missing ancestors")
+	}
+
+	if gossip.Enabled(INFO, "x") || !gossip.Enabled(WARNING, "x") {
+		t.Error("gossip should inherit WARNING from hier")
+	}
+
+	p2p.SetLevel(DEBUG)
+	if !gossip.Enabled(DEBUG, "x") || root.Enabled(INFO, "x") {
+		t.Error("gossip should inherit DEBUG from hier.p2p")
+	}
+
+	gossip.SetLevel(ERROR)
+	p2p.SetLevel(TRACE)
+	if gossip.Enabled(WARNING, "x") {
+		t.Error("explicit level should not be overridden by ancestors")
+	}
+
+	gossip.InheritLevel()
+	p2p.InheritLevel()
+	if !gossip.Enabled(WARNING, "x") || gossip.Enabled(INFO, "x") {
+		t.Error("InheritLevel should restore the nearest configured ancestor's level")
+	}
+}
+
+func TestPropagate(t *testing.T) {
+	resetRegistry(t)
+	var root, db, slow bytes.Buffer
+	parent := GetLogger("prop")
+	parent.SetOutput(&root)
+	parent.SetPropagate(false)
+
+	child := GetLogger("prop.db")
+	child.SetOutput(&db)
+	child.SetFormatter(&JSONFormatter{})
+	leaf := GetLogger("prop.db.slow")
+
+	leaf.Info("via ancestors")
+	child.Info("merged")
+	parent.SetLevel(ERROR) /* 写入上级时不经过上级的级别过滤 */
+	child.SetLevel(INFO)
+	child.SetPropagate(false)
+	child.Info("isolated")
+	leaf.SetOutput(&slow)
+	leaf.Info("own and parent")
+
+	if s := root.String(); !strings.Contains(s, "[prop.db.slow: TestPropagate] via ancestors") || !strings.Contains(s, "[prop.db: TestPropagate] merged") || strings.Contains(s, "isolated") || strings.Contains(s, "own and parent") {
+		t.Errorf("unexpected root output: %q", s)
+	}
+	if s := db.String(); strings.Count(s, `"message"`) != 4 || !strings.Contains(s, `"tag":"prop.db.slow"`) {
+		t.Errorf("unexpected prop.db output: %q", s)
+	}
+	if s := slow.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "own and parent") {
+		t.Errorf("unexpected prop.db.slow output: %q", s)
+	}
+
+	c := leaf.Clone()
+	c.SetOutput(io.Discard)
+	c.Info("from clone")
+	if !strings.Contains(db.String(), "from clone") {
+		t.Error("clone of a named logger should still propagate")
+	}
+}
+
+func TestNamedLoggerExplicitTags(t *testing.T) {
+	resetRegistry(t)
+
+	var buf bytes.Buffer
+	l := GetLogger("svc")
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.SetPropagate(false)
+	l.SetLevel(INFO)
+	l.SetTagLevel(DEBUG, "scheduler")
+
+	l.Tagged("scheduler").Debugf("tick %d", 1)
+	l.Debug("hidden")
+	l.LogEntry(&Entry{Level: INFO, Tag: "sql", Message: "query"})
+	l.LogEntry(&Entry{Level: INFO, Message: "untagged"})
+	srv := httptest.NewServer(l.AccessLog(http.NotFoundHandler(), 0))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	s := buf.String()
+	for _, want := range []string{"[scheduler: TestNamedLoggerExplicitTags] tick 1", "[sql: ] query", "[svc: ] untagged", "[http: AccessLog] GET /missing"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q in %q", want, s)
+		}
+	}
+	if strings.Contains(s, "hidden") {
+		t.Errorf("unexpected output: %q", s)
+	}
+}
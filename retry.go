@@ -0,0 +1,125 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* 重试写入的配置 */
+type RetryConfig struct {
+	Attempts   int              /* 每次写入最多尝试的次数，默认3 */
+	Backoff    time.Duration    /* 首次重试前的等待时间，之后每次加倍，默认100毫秒 */
+	MaxBackoff time.Duration    /* 等待时间的上限，默认5秒 */
+	Retryable  func(error) bool /* 判断错误是否可重试，为nil时所有错误都重试 */
+}
+
+/* 写入失败时以带随机抖动的指数退避重试的输出目标，用于偶尔断开的网络输出目标 */
+/* 重试用尽后视为不健康，之后每次写入只尝试一次，成功后恢复，可与FallbackWriter组合使用 */
+/* 退避等待期间Write不返回，Logger写入时持有写锁，所有记录日志的goroutine都会被阻塞，因此应置于AsyncWriter之后： */
+/* zlog.SetOutput(zlog.NewAsyncWriter(zlog.NewRetryWriter(conn, zlog.RetryConfig{}), zlog.AsyncConfig{Policy: zlog.DropOldest})) */
+type RetryWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	cfg    RetryConfig
+	failed int32 /* 是否不健康，原子读写 */
+}
+
+func NewRetryWriter(w io.Writer, cfg RetryConfig) *RetryWriter {
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	return &RetryWriter{w: w, cfg: cfg}
+}
+
+/* 是否因重试用尽而不健康 */
+func (r *RetryWriter) Failed() bool {
+	return atomic.LoadInt32(&r.failed) == 1
+}
+
+/* 在[d/2, d]内随机取值，避免多个进程同时重试 */
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (r *RetryWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := r.cfg.Attempts
+	if atomic.LoadInt32(&r.failed) == 1 {
+		attempts = 1
+	}
+
+	var (
+		written int
+		err     error
+	)
+	backoff := r.cfg.Backoff
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(jitter(backoff))
+			if backoff *= 2; backoff > r.cfg.MaxBackoff {
+				backoff = r.cfg.MaxBackoff
+			}
+		}
+
+		/* 部分写入时只重试剩余的内容 */
+		var n int
+		n, err = r.w.Write(p[written:])
+		written += n
+		if err == nil {
+			atomic.StoreInt32(&r.failed, 0)
+			return written, nil
+		}
+		if r.cfg.Retryable != nil && !r.cfg.Retryable(err) {
+			break
+		}
+	}
+
+	atomic.StoreInt32(&r.failed, 1)
+	return written, err
+}
+
+func (r *RetryWriter) Flush() error {
+	return flushWriter(r.w)
+}
+
+func (r *RetryWriter) Sync() error {
+	return syncWriter(r.w)
+}
+
+/* 标准输出及标准错误不会被关闭 */
+func (r *RetryWriter) Close() error {
+	return closeWriter(r.w)
+}
@@ -0,0 +1,112 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* 前fails次写入失败的输出目标，每次失败前写入一个字节 */
+type failingWriter struct {
+	fails  int
+	writes int
+	data   []byte
+	err    error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes <= w.fails {
+		w.data = append(w.data, p[0])
+		return 1, w.err
+	}
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
+
+func TestRetryWriter(t *testing.T) {
+	fw := &failingWriter{fails: 2, err: errors.New("reset by peer")}
+	r := NewRetryWriter(fw, RetryConfig{Backoff: time.Millisecond})
+
+	n, err := r.Write([]byte("hello\n"))
+	if err != nil || n != 6 || string(fw.data) != "hello\n" || fw.writes != 3 {
+		t.Fatalf("n=%d err=%v data=%q writes=%d", n, err, fw.data, fw.writes)
+	}
+	if r.Failed() {
+		t.Error("failed after success")
+	}
+
+	fw = &failingWriter{fails: 10, err: errors.New("reset by peer")}
+	r = NewRetryWriter(fw, RetryConfig{Attempts: 4, Backoff: time.Millisecond})
+	if _, err := r.Write([]byte("aaaa\n")); err == nil || !r.Failed() || fw.writes != 4 {
+		t.Fatalf("err=%v failed=%v writes=%d", err, r.Failed(), fw.writes)
+	}
+	r.Write([]byte("b\n"))
+	if fw.writes != 5 {
+		t.Errorf("unhealthy writer retried: %d writes", fw.writes)
+	}
+
+	fw = &failingWriter{fails: 10, err: io.ErrClosedPipe}
+	r = NewRetryWriter(fw, RetryConfig{Backoff: time.Millisecond, Retryable: func(err error) bool { return err != io.ErrClosedPipe }})
+	if _, err := r.Write([]byte("x\n")); err != io.ErrClosedPipe || fw.writes != 1 {
+		t.Errorf("permanent error retried: err=%v writes=%d", err, fw.writes)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := jitter(100 * time.Millisecond); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("jitter out of range: %s", d)
+		}
+	}
+}
+
+/* 退避等待不应阻塞经AsyncWriter记录日志的调用者 */
+func TestRetryBehindAsync(t *testing.T) {
+	fw := &failingWriter{fails: 2, err: errors.New("reset by peer")}
+	a := NewAsyncWriter(NewRetryWriter(fw, RetryConfig{Backoff: 100 * time.Millisecond}), AsyncConfig{})
+
+	l := NewLogger()
+	l.SetOutput(a)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		l.Infoln("entry", i)
+	}
+	if d := time.Since(start); d >= 50*time.Millisecond {
+		t.Errorf("logging blocked for %v during backoff", d)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(fw.data), "entry"); n != 10 {
+		t.Errorf("wrote %d entries: %q", n, fw.data)
+	}
+}
@@ -0,0 +1,169 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"strings"
+)
+
+/* 级别在[min, max]内的日志写入w */
+type route struct {
+	min, max uint8
+	w        io.Writer
+}
+
+/* 标志为tag或其下级的日志额外写入w */
+type tagRoute struct {
+	tag string
+	w   io.Writer
+}
+
+/* 判断标志是否为prefix或其下级，如fpay/consensus/raft属于fpay/consensus */
+func matchTag(tag, prefix string) bool {
+	return tag == prefix || strings.HasPrefix(tag, prefix) && tag[len(prefix)] == '/'
+}
+
+/* 将级别在[min, max]内的日志输出到w，可多次调用组合路由 */
+/* 如 Route(DEBUG, DEBUG, file); Route(INFO, SILENCE, os.Stdout); Route(ERROR, SILENCE, conn) */
+/* 日志写入所有匹配的路由，未匹配任何路由的日志仍输出到SetOutput及SetErrorOutput设置的目标 */
+func (l *Logger) Route(min, max uint8, w io.Writer) {
+	l.mu.Lock()
+	l.routes = append(l.routes, route{min: min, max: max, w: w})
+	l.mu.Unlock()
+}
+
+/* 将指定标志及其下级的日志额外输出到w，如 SetTagOutput(file, "fpay/consensus") */
+/* 这些日志仍同时输出到默认输出目标或匹配的路由，级别过滤与SetTagLevel相同 */
+func (l *Logger) SetTagOutput(w io.Writer, tags ...string) {
+	l.mu.Lock()
+	for _, tag := range tags {
+		l.tagRoutes = append(l.tagRoutes, tagRoute{tag: tag, w: w})
+	}
+	l.mu.Unlock()
+}
+
+/* 清除所有输出路由及标志的专用输出目标 */
+func (l *Logger) ClearRoutes() {
+	l.mu.Lock()
+	l.routes = nil
+	l.tagRoutes = nil
+	l.mu.Unlock()
+}
+
+/* 为默认日志记录器添加输出路由 */
+func Route(min, max uint8, w io.Writer) {
+	std.Route(min, max, w)
+}
+
+/* 将默认日志记录器中指定标志及其下级的日志额外输出到w */
+func SetTagOutput(w io.Writer, tags ...string) {
+	std.SetTagOutput(w, tags...)
+}
+
+/* 清除默认日志记录器的输出路由 */
+func ClearRoutes() {
+	std.ClearRoutes()
+}
+
+/* 使用独立格式的输出目标 */
+type formattedWriter struct {
+	w io.Writer
+	f Formatter
+}
+
+/* 返回以f格式写入w的输出目标，用于同一Logger的不同输出目标使用不同格式 */
+/* 如 zlog.SetOutput(zlog.WithFormatter(file, &zlog.JSONFormatter{})); zlog.Route(INFO, SILENCE, os.Stdout) */
+func WithFormatter(w io.Writer, f Formatter) io.Writer {
+	return &formattedWriter{w: w, f: f}
+}
+
+func (fw *formattedWriter) WriteEntry(e *Entry) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	fw.f.Format(buf, e)
+	if _, ok := fw.f.(binaryFormatter); !ok {
+		if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	_, err := fw.w.Write(buf.Bytes())
+	return err
+}
+
+func (fw *formattedWriter) Write(p []byte) (int, error) {
+	return fw.w.Write(p)
+}
+
+func (fw *formattedWriter) Flush() error {
+	return flushWriter(fw.w)
+}
+
+func (fw *formattedWriter) Sync() error {
+	return syncWriter(fw.w)
+}
+
+func (fw *formattedWriter) QueueDepth() int {
+	return queueDepth(fw.w)
+}
+
+func (fw *formattedWriter) Dropped() uint64 {
+	return droppedCount(fw.w)
+}
+
+/* 标准输出及标准错误不会被关闭 */
+func (fw *formattedWriter) Close() error {
+	return closeWriter(fw.w)
+}
+
+/* 指定标志及其下级的日志使用的格式 */
+type tagFormatter struct {
+	tag string
+	f   Formatter
+}
+
+/* 指定标志及其下级的日志使用f格式，多个标志匹配时使用最长的，WithFormatter包装的输出目标不受影响 */
+func (l *Logger) SetTagFormatter(f Formatter, tags ...string) {
+	l.mu.Lock()
+	for _, tag := range tags {
+		l.tagFormatters = append(l.tagFormatters, tagFormatter{tag: tag, f: f})
+	}
+	l.mu.Unlock()
+}
+
+/* 返回标志使用的格式，调用方需持有l.mu的读锁 */
+func (l *Logger) formatterFor(tag string) Formatter {
+	f, longest := l.formatter, -1
+	for _, tf := range l.tagFormatters {
+		if len(tf.tag) > longest && matchTag(tag, tf.tag) {
+			f, longest = tf.f, len(tf.tag)
+		}
+	}
+	return f
+}
+
+/* 默认日志记录器中指定标志及其下级的日志使用f格式 */
+func SetTagFormatter(f Formatter, tags ...string) {
+	std.SetTagFormatter(f, tags...)
+}
@@ -0,0 +1,135 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	var def, file, console, network bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&def)
+	l.Route(DEBUG, DEBUG, &file)
+	l.Route(INFO, SILENCE, &console)
+	l.Route(ERROR, SILENCE, &network)
+
+	l.Traceln("trace")
+	l.Debugln("debug")
+	l.Infoln("info")
+	l.Errorln("error")
+
+	for _, c := range []struct {
+		name string
+		buf  *bytes.Buffer
+		want []string
+		deny []string
+	}{
+		{"default", &def, []string{"trace"}, []string{"debug", "info", "error"}},
+		{"file", &file, []string{"debug"}, []string{"trace", "info", "error"}},
+		{"console", &console, []string{"info", "error"}, []string{"trace", "debug"}},
+		{"network", &network, []string{"error"}, []string{"trace", "debug", "info"}},
+	} {
+		out := c.buf.String()
+		for _, w := range c.want {
+			if !strings.Contains(out, "] "+w) {
+				t.Errorf("%s: missing %q in %q", c.name, w, out)
+			}
+		}
+		for _, d := range c.deny {
+			if strings.Contains(out, "] "+d) {
+				t.Errorf("%s: unexpected %q in %q", c.name, d, out)
+			}
+		}
+	}
+}
+
+func TestTagOutput(t *testing.T) {
+	var def, consensus bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&def)
+	l.SetTagOutput(&consensus, "fpay/consensus")
+
+	l.Tagged("fpay/consensus").Infoln("vote")
+	l.Tagged("fpay/consensus/raft").Infoln("elect")
+	l.Tagged("fpay/consensusx").Infoln("other")
+	l.Tagged("fpay/p2p").Infoln("dial")
+
+	if out := consensus.String(); !strings.Contains(out, "] vote") || !strings.Contains(out, "] elect") || strings.Contains(out, "] other") || strings.Contains(out, "] dial") {
+		t.Errorf("consensus = %q", out)
+	}
+	if out := def.String(); strings.Count(out, "\n") != 4 {
+		t.Errorf("default = %q", out)
+	}
+
+	l.ClearRoutes()
+	l.Tagged("fpay/consensus").Infoln("after")
+	if strings.Contains(consensus.String(), "after") {
+		t.Error("tag output not cleared")
+	}
+}
+
+func TestWithFormatter(t *testing.T) {
+	var console, file bytes.Buffer
+	l := NewLogger()
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.SetOutput(&console)
+	l.Route(INFO, SILENCE, &console)
+	l.Route(INFO, SILENCE, WithFormatter(&file, &JSONFormatter{}))
+	l.Infoln("started")
+
+	if !strings.Contains(console.String(), "[INFO][zlog: TestWithFormatter] started") {
+		t.Errorf("console = %q", console.String())
+	}
+
+	var e entryJSON
+	if err := json.Unmarshal(file.Bytes(), &e); err != nil {
+		t.Fatalf("file = %q: %v", file.String(), err)
+	}
+	if e.Level != "INFO" || e.Message != "started" || e.Func != "TestWithFormatter" || e.Line == 0 {
+		t.Errorf("file entry = %+v", e)
+	}
+}
+
+func TestTagFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.SetTagFormatter(&JSONFormatter{}, "fpay")
+	l.SetTagFormatter(&TextFormatter{NoColor: true, Caller: CallerTag}, "fpay/p2p")
+
+	l.Tagged("fpay/db").Infoln("json")
+	l.Tagged("fpay/p2p/dial").Infoln("short")
+	l.Tagged("other").Infoln("text")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "{") || !strings.HasSuffix(lines[1], "[INFO][dial] short") || !strings.HasSuffix(lines[2], "[other: TestTagFormatter] text") {
+		t.Errorf("lines = %q", lines)
+	}
+}
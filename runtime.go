@@ -0,0 +1,111 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/* 返回当前进程的运行时指标字段：goroutine数、堆内存、GC次数及最近一次GC的暂停时间、打开的文件描述符数 */
+/* 无法读取文件描述符数的系统(非Linux)不返回fds */
+func RuntimeFields() []Field {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fields := []Field{
+		F("goroutines", runtime.NumGoroutine()),
+		F("heap_alloc", m.HeapAlloc),
+		F("heap_sys", m.HeapSys),
+		F("gc", m.NumGC),
+	}
+	if m.NumGC > 0 {
+		fields = append(fields, F("gc_pause", time.Duration(m.PauseNs[(m.NumGC+255)%256])))
+	}
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		fields = append(fields, F("fds", len(fds)))
+	}
+	return fields
+}
+
+var startTime = time.Now() /* 进程启动时间的近似值，用于计算运行时长 */
+
+const defaultStatsInterval = time.Minute /* LogRuntimeStats及StartHeartbeat的interval不大于0时使用的间隔 */
+
+/* 每隔interval在后台goroutine中调用fn，interval不大于0时为defaultStatsInterval，返回的函数停止调用 */
+func every(interval time.Duration, fn func()) func() {
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+/* 每隔interval以level级别输出一条运行时指标日志，用于没有监控系统的主机，返回的函数停止输出 */
+/* interval不大于0时为1分钟，用法：defer zlog.LogRuntimeStats(DEBUG, time.Minute)() */
+func (l *Logger) LogRuntimeStats(level uint8, interval time.Duration) func() {
+	return every(interval, func() {
+		if l.wants(level, "zlog", "") {
+			l.output(newEntry(level, "zlog", "runtime", "runtime stats", RuntimeFields()...))
+		}
+	})
+}
+
+/* 默认日志记录器每隔interval输出一条运行时指标日志，返回的函数停止输出 */
+func LogRuntimeStats(level uint8, interval time.Duration) func() {
+	return std.LogRuntimeStats(level, interval)
+}
+
+/* 每隔interval输出一条INFO级别的alive日志，包含运行时长及已输出、丢弃的条数，返回的函数停止输出 */
+/* 日志监控可据此发现输出停止的挂起进程，interval不大于0时为1分钟 */
+func (l *Logger) StartHeartbeat(interval time.Duration) func() {
+	return every(interval, func() {
+		s := l.Stats()
+		uptime := time.Since(startTime).Round(time.Second)
+		l.output(newEntry(INFO, "zlog", "heartbeat", "alive", F("uptime", uptime), F("entries", s.Entries), F("dropped", s.Dropped)))
+	})
+}
+
+/* 默认日志记录器每隔interval输出一条alive日志，返回的函数停止输出 */
+func StartHeartbeat(interval time.Duration) func() {
+	return std.StartHeartbeat(interval)
+}
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogRuntimeStats(t *testing.T) {
+	var cw countingWriter
+	l := NewLogger()
+	l.SetOutput(&cw)
+
+	stop := l.LogRuntimeStats(INFO, 5*time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if out, _ := cw.snapshot(); strings.Contains(out, "] runtime stats goroutines=") {
+			if !strings.Contains(out, " heap_alloc=") || !strings.Contains(out, " gc=") {
+				t.Fatalf("missing fields: %q", out)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no runtime stats logged")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+
+	time.Sleep(10 * time.Millisecond)
+	_, before := cw.snapshot()
+	time.Sleep(20 * time.Millisecond)
+	if _, after := cw.snapshot(); after != before {
+		t.Errorf("stats logged after stop: %d -> %d", before, after)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var cw countingWriter
+	l := NewLogger()
+	l.SetOutput(&cw)
+	l.Infoln("before")
+
+	stop := l.StartHeartbeat(5 * time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if out, _ := cw.snapshot(); strings.Contains(out, "[zlog: heartbeat] alive uptime=") {
+			if !strings.Contains(out, " entries=1 dropped=0\n") {
+				t.Fatalf("counters: %q", out)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no heartbeat logged")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEveryDefaultInterval(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+	l.StartHeartbeat(0)()
+	l.LogRuntimeStats(INFO, -time.Second)()
+}
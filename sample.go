@@ -0,0 +1,130 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/* 返回Oncef等函数的调用处的计数，首次调用返回1，调用处计入AddCallerSkip跳过的层数 */
+/* 该级别的日志在调用处不会输出时不计数并返回0，以免调低级别后首次的日志已被计入 */
+func (l *Logger) siteCount(level uint8) uint64 {
+	if !l.mayLog(level) {
+		return 0
+	}
+	if c := l.caller(2, 0); !l.enabledAt(level, c.pkg, c.file) {
+		return 0
+	}
+
+	pc, _ := callerAt(l.skip(2, 0))
+	counter, _ := l.sites.LoadOrStore(pc, new(uint64))
+	return atomic.AddUint64(counter.(*uint64), 1)
+}
+
+/* 每个调用处只在首次执行时输出日志，适用于热循环中的告警 */
+func (l *Logger) Oncef(level uint8, format string, v ...interface{}) {
+	if l.siteCount(level) == 1 {
+		l.logf(0, level, format, v...)
+	}
+}
+
+/* 每个调用处每执行n次输出一次日志，首次执行时输出 */
+func (l *Logger) Everyf(n uint64, level uint8, format string, v ...interface{}) {
+	if c := l.siteCount(level); n > 0 && c > 0 && (c-1)%n == 0 {
+		l.logf(0, level, format, v...)
+	}
+}
+
+/* 每个调用处只在首次执行时输出日志 */
+func Oncef(level uint8, format string, v ...interface{}) {
+	if std.siteCount(level) == 1 {
+		std.logf(0, level, format, v...)
+	}
+}
+
+/* 每个调用处每执行n次输出一次日志，首次执行时输出 */
+func Everyf(n uint64, level uint8, format string, v ...interface{}) {
+	if c := std.siteCount(level); n > 0 && c > 0 && (c-1)%n == 0 {
+		std.logf(0, level, format, v...)
+	}
+}
+
+/* 按调用处限制输出频率的采样规则 */
+type sampler struct {
+	first      uint64
+	thereafter uint64
+	tick       time.Duration
+	counters   sync.Map /* sampleKey -> *sampleCounter */
+}
+
+/* 采样的调用处，源文件未知时以日志内容区分 */
+type sampleKey struct {
+	file string
+	line int
+	msg  string
+}
+
+type sampleCounter struct {
+	reset int64  /* 本周期结束的时间，Unix纳秒，原子读写 */
+	n     uint64 /* 本周期内的条数，原子读写 */
+}
+
+func (s *sampler) allow(e *Entry) bool {
+	key := sampleKey{file: e.File, line: e.Line}
+	if e.File == "" {
+		key.msg = e.Message
+	}
+
+	v, _ := s.counters.LoadOrStore(key, new(sampleCounter))
+	c := v.(*sampleCounter)
+	now := e.Time.UnixNano()
+	if reset := atomic.LoadInt64(&c.reset); now >= reset && atomic.CompareAndSwapInt64(&c.reset, reset, now+int64(s.tick)) {
+		atomic.StoreUint64(&c.n, 0)
+	}
+
+	n := atomic.AddUint64(&c.n, 1)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+/* 每个调用处每个tick周期内输出前first条日志，之后每thereafter条输出一条，thereafter为0时丢弃其余 */
+/* ERROR及以上级别的日志不采样，first为0时取消采样 */
+func (l *Logger) SetSampling(first, thereafter uint64, tick time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if first == 0 {
+		l.sampler = nil
+		return
+	}
+	l.sampler = &sampler{first: first, thereafter: thereafter, tick: tick}
+}
+
+/* 为默认日志记录器设置采样规则 */
+func SetSampling(first, thereafter uint64, tick time.Duration) {
+	std.SetSampling(first, thereafter, tick)
+}
@@ -0,0 +1,129 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnceEvery(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	for i := 0; i < 10; i++ {
+		l.Oncef(WARNING, "once %d", i)
+		l.Everyf(4, INFO, "every %d", i)
+	}
+	l.Oncef(WARNING, "once at another site")
+
+	out := buf.String()
+	if strings.Count(out, "once") != 2 || !strings.Contains(out, "once 0") {
+		t.Errorf("Oncef: %q", out)
+	}
+	for _, want := range []string{"every 0", "every 4", "every 8"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Everyf missing %q: %q", want, out)
+		}
+	}
+	if strings.Count(out, "every") != 3 {
+		t.Errorf("Everyf: %q", out)
+	}
+}
+
+func TestOnceLevelAndSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(INFO)
+
+	/* 未输出的级别不计数 */
+	for i := 0; i < 2; i++ {
+		l.Oncef(DEBUG, "debug once %d", i)
+		if i == 0 {
+			l.SetLevel(DEBUG)
+		}
+	}
+	if out := buf.String(); !strings.Contains(out, "debug once 1") {
+		t.Errorf("Oncef counted a disabled level: %q", out)
+	}
+
+	/* 封装函数以AddCallerSkip区分其调用处 */
+	buf.Reset()
+	l.AddCallerSkip(1)
+	warnOnce := func(msg string) { l.Oncef(WARNING, "%s", msg) }
+	warnOnce("site a")
+	warnOnce("site b")
+	if out := buf.String(); !strings.Contains(out, "site a") || !strings.Contains(out, "site b") {
+		t.Errorf("Oncef ignored callerSkip: %q", out)
+	}
+
+	/* Helper标记的封装函数同样按其调用处计数 */
+	buf.Reset()
+	l.AddCallerSkip(-1)
+	helperOnce(l, "helper a")
+	helperOnce(l, "helper b")
+	if out := buf.String(); !strings.Contains(out, "helper a") || !strings.Contains(out, "helper b") {
+		t.Errorf("Oncef ignored Helper: %q", out)
+	}
+}
+
+func helperOnce(l *Logger, msg string) {
+	Helper()
+	l.Oncef(WARNING, "%s", msg)
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetSampling(2, 3, time.Hour)
+
+	for i := 1; i <= 8; i++ {
+		l.Infof("hot %d", i)
+		l.Errorf("err %d", i)
+	}
+
+	out := buf.String()
+	for i := 1; i <= 8; i++ {
+		want := i <= 2 || i == 5 || i == 8
+		if got := strings.Contains(out, fmt.Sprintf("hot %d\n", i)); got != want {
+			t.Errorf("hot %d logged = %v, want %v", i, got, want)
+		}
+		if !strings.Contains(out, fmt.Sprintf("err %d\n", i)) {
+			t.Errorf("err %d should not be sampled", i)
+		}
+	}
+
+	l.SetSampling(0, 0, 0)
+	l.Infof("hot %d", 9)
+	if !strings.Contains(buf.String(), "hot 9") {
+		t.Error("sampling not disabled")
+	}
+}
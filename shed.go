@@ -0,0 +1,127 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+/* 负载过高时按级别丢弃日志的配置，QueueDepth及Latency至少设置一项 */
+/* 超过阈值时丢弃INFO以下的日志，超过阈值两倍时丢弃WARNING以下的日志，WARNING及以上总是保留 */
+type ShedConfig struct {
+	QueueDepth int           /* 各输出目标队列中等待发送的日志条数之和的阈值，为0时不检查 */
+	Latency    time.Duration /* 最近一条日志的输出耗时的阈值，为0时不检查 */
+	Interval   time.Duration /* 检查负载的最小间隔，默认100毫秒 */
+}
+
+/* 负载检查的状态，原子读写 */
+type shedder struct {
+	cfg     ShedConfig
+	tier    int32  /* 0为正常，1丢弃INFO以下，2丢弃WARNING以下 */
+	checked int64  /* 上次检查的时间，Unix纳秒 */
+	latency int64  /* 最近一条日志的输出耗时，纳秒 */
+	shed    uint64 /* 本次高负载期间丢弃的条数 */
+	total   uint64 /* 累计丢弃的条数 */
+}
+
+/* 负载过高时优先丢弃低级别的日志，负载恢复后输出一条WARNING汇总丢弃的条数 */
+/* 用于AsyncWriter、远端服务等有队列的输出目标，cfg为零值时取消 */
+func (l *Logger) SetLoadShedding(cfg ShedConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 100 * time.Millisecond
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cfg.QueueDepth <= 0 && cfg.Latency <= 0 {
+		l.shedder = nil
+		return
+	}
+	l.shedder = &shedder{cfg: cfg}
+}
+
+/* 为默认日志记录器设置负载过高时的丢弃规则 */
+func SetLoadShedding(cfg ShedConfig) {
+	std.SetLoadShedding(cfg)
+}
+
+/* 返回当前的负载等级 */
+func (s *shedder) pressure(l *Logger) int32 {
+	depth := 0
+	if s.cfg.QueueDepth > 0 {
+		for _, w := range l.writers() {
+			depth += queueDepth(w)
+		}
+	}
+	latency := time.Duration(atomic.LoadInt64(&s.latency))
+
+	over := func(v, limit int64) int32 {
+		switch {
+		case limit <= 0 || v < limit:
+			return 0
+		case v < 2*limit:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	tier := over(int64(depth), int64(s.cfg.QueueDepth))
+	if t := over(int64(latency), int64(s.cfg.Latency)); t > tier {
+		tier = t
+	}
+	return tier
+}
+
+/* 判断是否因负载过高丢弃e，按间隔重新检查负载，负载恢复时输出汇总 */
+func (l *Logger) shedding(s *shedder, e *Entry) bool {
+	now := e.Time.UnixNano()
+	if last := atomic.LoadInt64(&s.checked); now-last >= int64(s.cfg.Interval) && atomic.CompareAndSwapInt64(&s.checked, last, now) {
+		tier := s.pressure(l)
+		if old := atomic.SwapInt32(&s.tier, tier); old > 0 && tier == 0 {
+			if n := atomic.SwapUint64(&s.shed, 0); n > 0 {
+				l.output(newEntry(WARNING, "zlog", "shed", fmt.Sprintf("shed %d entries under load", n), F("shed", n)))
+			}
+		}
+	}
+
+	switch atomic.LoadInt32(&s.tier) {
+	case 1:
+		if e.Level >= INFO {
+			return false
+		}
+	case 2:
+		if e.Level >= WARNING {
+			return false
+		}
+	default:
+		return false
+	}
+
+	atomic.AddUint64(&s.shed, 1)
+	atomic.AddUint64(&s.total, 1)
+	return true
+}
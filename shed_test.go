@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* 队列长度可控的输出目标 */
+type depthWriter struct {
+	bytes.Buffer
+	depth int
+}
+
+func (w *depthWriter) QueueDepth() int {
+	return w.depth
+}
+
+func TestLoadShedding(t *testing.T) {
+	w := new(depthWriter)
+	l := NewLogger()
+	l.SetOutput(w)
+	l.SetLoadShedding(ShedConfig{QueueDepth: 10, Interval: time.Nanosecond})
+
+	logAll := func(step string) {
+		l.Debugln(step + " debug")
+		l.Infoln(step + " info")
+		l.Warningln(step + " warning")
+	}
+
+	logAll("normal")
+	w.depth = 10
+	logAll("busy")
+	w.depth = 25
+	logAll("overload")
+	w.depth = 0
+	logAll("recovered")
+
+	out := w.String()
+	for _, want := range []string{"normal debug", "normal info", "busy info", "busy warning", "overload warning", "shed 3 entries under load shed=3", "recovered debug"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	for _, deny := range []string{"busy debug", "overload debug", "overload info"} {
+		if strings.Contains(out, deny) {
+			t.Errorf("unexpected %q in %q", deny, out)
+		}
+	}
+	if s := l.Stats(); s.Shed != 3 {
+		t.Errorf("stats: %+v", s)
+	}
+}
@@ -0,0 +1,101 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+/* 按名称(不区分大小写)或数值解析日志级别，如"info"、"WARNING"、"35" */
+func ParseLevel(name string) (uint8, error) {
+	name = strings.TrimSpace(name)
+	for level, levelName := range LogLevelNames {
+		if levelName != "" && strings.EqualFold(levelName, name) {
+			return uint8(level), nil
+		}
+	}
+
+	if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+		return uint8(n), nil
+	}
+	return 0, fmt.Errorf("zlog: unknown level %q", name)
+}
+
+/* 按"*=info,fpay/p2p=verbose,fpay/db=error"形式的配置设置全局及标志级别 */
+/* *或省略标志的项设置全局级别；配置将整体替换原有的标志级别，解析出错时不做任何修改 */
+func (l *Logger) SetSpec(spec string) error {
+	global, explicit := uint8(atomic.LoadUint32(&l.level)), false
+	levels := make(map[string]uint8)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		tag, name := "*", item
+		if i := strings.LastIndexByte(item, '='); i >= 0 {
+			tag, name = strings.TrimSpace(item[:i]), item[i+1:]
+		}
+
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+
+		if tag == "*" || tag == "" {
+			global, explicit = level, true
+		} else {
+			levels[tag] = level
+		}
+	}
+
+	l.mu.Lock()
+	atomic.StoreUint32(&l.level, uint32(global))
+	l.levelSet = l.levelSet || explicit
+	l.tagLevels.Store(levels)
+	l.updateMinLevel()
+	l.mu.Unlock()
+
+	if explicit && (l == std || l.parent != nil) {
+		inheritLevels()
+	}
+	return nil
+}
+
+/* 按配置设置默认日志记录器的全局及标志级别 */
+func SetSpec(spec string) error {
+	return std.SetSpec(spec)
+}
+
+/* 读取环境变量中的级别配置，如ZLOG="*=info,fpay/p2p=verbose"，变量为空时不做修改 */
+func SetSpecFromEnv(key string) error {
+	spec := os.Getenv(key)
+	if spec == "" {
+		return nil
+	}
+	return std.SetSpec(spec)
+}
@@ -0,0 +1,77 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]uint8{"info": INFO, " Warning ": WARNING, "SILENCE": SILENCE, "35": 35} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %d, %v", name, got, err)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
+
+func TestSetSpec(t *testing.T) {
+	l := NewLogger()
+	l.SetTagLevel(VERBOSE, "stale")
+	if err := l.SetSpec("*=info, fpay/p2p=verbose,fpay/db=error"); err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Enabled(DEBUG, "fpay") || !l.Enabled(INFO, "fpay") {
+		t.Error("global level not applied")
+	}
+	if !l.Enabled(VERBOSE, "fpay/p2p/gossip") || l.Enabled(WARNING, "fpay/db") {
+		t.Error("tag levels not applied")
+	}
+	if l.Enabled(DEBUG, "stale") {
+		t.Error("previous tag levels not replaced")
+	}
+
+	if err := l.SetSpec("warning,fpay/db=loud"); err == nil {
+		t.Fatal("invalid spec accepted")
+	}
+	if !l.Enabled(INFO, "fpay") {
+		t.Error("invalid spec partially applied")
+	}
+}
+
+func TestSetSpecFromEnv(t *testing.T) {
+	t.Setenv("ZLOG_TEST_SPEC", "error")
+	defer SetLevel(VERBOSE)
+
+	if err := SetSpecFromEnv("ZLOG_TEST_SPEC"); err != nil {
+		t.Fatal(err)
+	}
+	if IsEnabled(WARNING) {
+		t.Error("env spec not applied")
+	}
+}
@@ -0,0 +1,110 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+/* 远端输出目标发送失败的日志的本地暂存文件，恢复后可通过各输出目标的Replay重新发送 */
+type SpillFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+/* 暂存文件中的一条记录，Line为按远端格式编码的日志 */
+type spillRecord struct {
+	Time  time.Time `json:"t"`
+	Level uint8     `json:"l"`
+	Tag   string    `json:"g,omitempty"`
+	Line  []byte    `json:"b"`
+}
+
+/* 使用path作为暂存文件，文件在首次暂存时创建 */
+func NewSpillFile(path string) *SpillFile {
+	return &SpillFile{path: path}
+}
+
+/* 追加发送失败的日志 */
+func (s *SpillFile) spill(items []batchItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err = enc.Encode(spillRecord{Time: item.time, Level: item.level, Tag: item.tag, Line: item.line}); err != nil {
+			break
+		}
+	}
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+/* 以send重新发送暂存的全部日志，成功后清空暂存文件，没有暂存时直接返回 */
+func (s *SpillFile) replay(send func([]batchItem) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var items []batchItem
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var r spillRecord
+		if err = dec.Decode(&r); err != nil {
+			break
+		}
+		items = append(items, batchItem{time: r.Time, level: r.Level, tag: r.Tag, line: r.Line})
+	}
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(items) > 0 {
+		if err := send(items); err != nil {
+			return err
+		}
+	}
+	return os.Remove(s.path)
+}
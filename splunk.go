@@ -0,0 +1,151 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* Splunk HTTP Event Collector的配置 */
+type SplunkConfig struct {
+	URL        string        /* HEC地址，如https://splunk:8088/services/collector/event */
+	Token      string        /* HEC令牌 */
+	Source     string        /* 不为空时设置事件的source */
+	SourceType string        /* 事件的sourcetype，默认为_json */
+	Index      string        /* 不为空时写入指定索引 */
+	Host       string        /* 不为空时设置事件的host */
+	BatchSize  int           /* 每批最多的条数，默认100 */
+	Interval   time.Duration /* 定时发送的间隔，默认1秒 */
+	Retries    int           /* 失败后的重试次数 */
+	Backoff    time.Duration /* 首次重试前的等待时间，之后每次加倍，默认1秒 */
+	MaxBackoff time.Duration /* 等待时间的上限，默认30秒 */
+	Client     *http.Client  /* 为nil时使用http.DefaultClient */
+	Spill      *SpillFile    /* 不为nil时发送最终失败或队列已满的日志写入该暂存文件，否则丢弃 */
+}
+
+/* 批量发送到Splunk HEC的输出目标 */
+type SplunkWriter struct {
+	cfg   SplunkConfig
+	batch *batcher
+}
+
+func NewSplunkWriter(cfg SplunkConfig) *SplunkWriter {
+	if cfg.SourceType == "" {
+		cfg.SourceType = "_json"
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	w := &SplunkWriter{cfg: cfg}
+	w.batch = newBatcher(cfg.BatchSize, cfg.Interval, cfg.Spill, w.send)
+	return w
+}
+
+/* HEC事件 */
+type splunkEvent struct {
+	Time       float64     `json:"time"`
+	Host       string      `json:"host,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	SourceType string      `json:"sourcetype"`
+	Index      string      `json:"index,omitempty"`
+	Event      interface{} `json:"event"`
+}
+
+func (w *SplunkWriter) event(t time.Time, v interface{}) ([]byte, error) {
+	return json.Marshal(splunkEvent{
+		Time:       float64(t.UnixNano()/int64(time.Millisecond)) / 1000,
+		Host:       w.cfg.Host,
+		Source:     w.cfg.Source,
+		SourceType: w.cfg.SourceType,
+		Index:      w.cfg.Index,
+		Event:      v,
+	})
+}
+
+func (w *SplunkWriter) WriteEntry(e *Entry) error {
+	ej := newEntryJSON(e)
+	line, err := w.event(e.Time, ej)
+	if err != nil {
+		return err
+	}
+	return w.batch.add(batchItem{time: e.Time, level: e.Level, tag: e.Tag, line: line})
+}
+
+/* 未经Logger写入的内容按行作为字符串事件发送 */
+func (w *SplunkWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	var aerr error
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		b, err := w.event(now, line)
+		if err != nil {
+			return 0, err
+		}
+		if err := w.batch.add(batchItem{time: now, level: SILENCE, line: b}); aerr == nil {
+			aerr = err
+		}
+	}
+	return len(p), aerr
+}
+
+/* 重新发送暂存文件中的日志，应在远端恢复后调用 */
+func (w *SplunkWriter) Replay() error {
+	return w.batch.replay()
+}
+
+/* 等待发送的日志条数 */
+func (w *SplunkWriter) QueueDepth() int {
+	return w.batch.queueDepth()
+}
+
+/* 发送缓冲的日志并等待完成 */
+func (w *SplunkWriter) Flush() error {
+	return w.batch.flush()
+}
+
+/* 发送剩余的日志并停止定时发送 */
+func (w *SplunkWriter) Close() error {
+	return w.batch.close()
+}
+
+func (w *SplunkWriter) send(items []batchItem) error {
+	var body bytes.Buffer
+	for _, item := range items {
+		body.Write(item.line)
+		body.WriteByte('\n')
+	}
+
+	header := http.Header{
+		"Authorization": {"Splunk " + w.cfg.Token},
+		"Content-Type":  {"application/json"},
+	}
+	_, err := postWithRetry(w.cfg.Client, w.cfg.URL, header, body.Bytes(), w.cfg.Retries, w.cfg.Backoff, w.cfg.MaxBackoff)
+	return err
+}
@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSplunkWriter(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if got := r.Header.Get("Authorization"); got != "Splunk secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var ev map[string]interface{}
+			if err := dec.Decode(&ev); err != nil {
+				t.Error(err)
+				return
+			}
+			events = append(events, ev)
+		}
+		w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer srv.Close()
+
+	sw := NewSplunkWriter(SplunkConfig{URL: srv.URL, Token: "secret", SourceType: "zlog", Index: "main", Interval: time.Hour})
+	l := NewLogger()
+	l.SetOutput(sw)
+	l.Infoln("payment accepted")
+	l.Errorln("payment declined")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %v", events)
+	}
+	ev := events[1]
+	body, _ := ev["event"].(map[string]interface{})
+	if ev["sourcetype"] != "zlog" || ev["index"] != "main" || body["level"] != "ERROR" || body["message"] != "payment declined" {
+		t.Errorf("event = %v", ev)
+	}
+
+	bad := NewSplunkWriter(SplunkConfig{URL: srv.URL, Token: "wrong", Retries: 3, Backoff: time.Hour})
+	bad.Write([]byte("denied\n"))
+	if err := bad.Close(); err == nil {
+		t.Error("expected permanent error for bad token")
+	}
+}
@@ -0,0 +1,124 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+/* 输出耗时分布的各区间上界，最后一个区间没有上界 */
+var latencyBounds = [...]time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+/* 耗时分布的一个区间 */
+type LatencyBucket struct {
+	Le    time.Duration /* 区间上界，为0时表示没有上界 */
+	Count uint64        /* 落在该区间的日志条数，不含更小的区间 */
+}
+
+/* 日志输出的统计，用于判断日志本身是否成为瓶颈 */
+type Stats struct {
+	Entries    uint64          /* 已输出的日志条数 */
+	Latency    []LatencyBucket /* 自记录日志至写完所有输出目标的耗时分布 */
+	QueueDepth int             /* 各输出目标队列中等待发送的日志条数之和 */
+	Dropped    uint64          /* 各输出目标因队列已满丢弃的日志条数之和 */
+	Shed       uint64          /* 因负载过高丢弃的日志条数，见SetLoadShedding */
+}
+
+/* 有发送队列的输出目标 */
+type queuer interface {
+	QueueDepth() int
+}
+
+/* 会丢弃日志的输出目标 */
+type dropper interface {
+	Dropped() uint64
+}
+
+/* 输出耗时的统计，原子读写 */
+type latencyStats struct {
+	counts [len(latencyBounds) + 1]uint64
+}
+
+func (s *latencyStats) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	atomic.AddUint64(&s.counts[i], 1)
+}
+
+/* 返回该Logger的输出统计 */
+func (l *Logger) Stats() Stats {
+	var s Stats
+	s.Latency = make([]LatencyBucket, len(l.latency.counts))
+	for i := range l.latency.counts {
+		if i < len(latencyBounds) {
+			s.Latency[i].Le = latencyBounds[i]
+		}
+		s.Latency[i].Count = atomic.LoadUint64(&l.latency.counts[i])
+		s.Entries += s.Latency[i].Count
+	}
+
+	l.mu.RLock()
+	if l.shedder != nil {
+		s.Shed = atomic.LoadUint64(&l.shedder.total)
+	}
+	l.mu.RUnlock()
+
+	for _, w := range l.writers() {
+		s.QueueDepth += queueDepth(w)
+		s.Dropped += droppedCount(w)
+	}
+	return s
+}
+
+/* 返回输出目标队列中等待发送的日志条数，没有队列时为0 */
+func queueDepth(w io.Writer) int {
+	if q, ok := w.(queuer); ok {
+		return q.QueueDepth()
+	}
+	return 0
+}
+
+/* 返回输出目标丢弃的日志条数，不会丢弃时为0 */
+func droppedCount(w io.Writer) uint64 {
+	if d, ok := w.(dropper); ok {
+		return d.Dropped()
+	}
+	return 0
+}
+
+/* 返回默认日志记录器的输出统计 */
+func GetStats() Stats {
+	return std.Stats()
+}
@@ -0,0 +1,77 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+/* 固定返回队列长度的输出目标 */
+type fixedQueue struct {
+	io.Writer
+	depth int
+}
+
+func (q fixedQueue) QueueDepth() int {
+	return q.depth
+}
+
+/* 固定返回丢弃条数的输出目标 */
+type fixedDrops struct {
+	io.Writer
+	dropped uint64
+}
+
+func (d fixedDrops) Dropped() uint64 {
+	return d.dropped
+}
+
+func TestStats(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(fixedQueue{io.Discard, 7})
+
+	l.Infoln("fast")
+	l.LogEntry(&Entry{Level: INFO, Time: time.Now().Add(-2 * time.Second), Message: "slow"})
+
+	s := l.Stats()
+	if s.Entries != 2 || s.QueueDepth != 7 {
+		t.Errorf("stats = %+v", s)
+	}
+	if last := s.Latency[len(s.Latency)-1]; last.Le != 0 || last.Count != 1 {
+		t.Errorf("last bucket = %+v", last)
+	}
+}
+
+func TestStatsWrapped(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(WithFilter(fixedQueue{io.Discard, 3}, FilterFunc(func(*Entry) bool { return true })))
+	l.Route(ERROR, SILENCE, WithFormatter(fixedDrops{io.Discard, 5}, JSON))
+
+	if s := l.Stats(); s.QueueDepth != 3 || s.Dropped != 5 {
+		t.Errorf("stats = %+v", s)
+	}
+}
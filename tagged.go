@@ -0,0 +1,196 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"fmt"
+	"strings"
+)
+
+/* 使用指定标志而非调用者包路径进行级别过滤的日志句柄 */
+type TagLogger struct {
+	logger *Logger
+	tag    string
+}
+
+/* 返回使用tag作为标志的日志句柄，如 l.Tagged("scheduler").Infof(...) */
+func (l *Logger) Tagged(tag string) *TagLogger {
+	return &TagLogger{logger: l, tag: tag}
+}
+
+/* 返回默认日志记录器上使用tag作为标志的日志句柄 */
+func Tagged(tag string) *TagLogger {
+	return std.Tagged(tag)
+}
+
+/* 判断以指定级别记录的日志是否会输出 */
+func (t *TagLogger) Enabled(level uint8) bool {
+	return t.logger.Enabled(level, t.tag)
+}
+
+func (t *TagLogger) output(c callerInfo, level uint8, msg string, fields []Field) {
+	e := c.entry(level, msg, fields...)
+	e.Tag = t.tag
+	t.logger.output(e)
+}
+
+func (t *TagLogger) logf(level uint8, format string, v ...interface{}) {
+	if c, ok := t.wants(level); ok {
+		msg, fields := sprintf(format, v)
+		t.output(c, level, msg, fields)
+	}
+}
+
+func (t *TagLogger) logln(level uint8, v ...interface{}) {
+	if c, ok := t.wants(level); ok {
+		t.output(c, level, strings.TrimSuffix(fmt.Sprintln(resolve(v)...), "\n"), nil)
+	}
+}
+
+func (t *TagLogger) logw(level uint8, msg string, fields []Field) {
+	if c, ok := t.wants(level); ok {
+		t.output(c, level, msg, fields)
+	}
+}
+
+/* 解析调用者并判断是否需要构造日志，调用层次须与logf等一致 */
+func (t *TagLogger) wants(level uint8) (callerInfo, bool) {
+	if !t.logger.mayLog(level) {
+		return callerInfo{}, false
+	}
+
+	c := t.logger.caller(3, 0)
+	return c, t.logger.wants(level, t.tag, c.file)
+}
+
+func (t *TagLogger) Logf(level uint8, format string, v ...interface{}) {
+	t.logf(level, format, v...)
+}
+
+func (t *TagLogger) Logln(level uint8, v ...interface{}) {
+	t.logln(level, v...)
+}
+
+/* 输出带结构化字段的日志，msg不会被当作格式串 */
+func (t *TagLogger) Logw(level uint8, msg string, fields ...Field) {
+	t.logw(level, msg, fields)
+}
+
+func (t *TagLogger) Verbosef(format string, v ...interface{}) {
+	t.logf(VERBOSE, format, v...)
+}
+
+func (t *TagLogger) Verboseln(v ...interface{}) {
+	t.logln(VERBOSE, v...)
+}
+
+func (t *TagLogger) Tracef(format string, v ...interface{}) {
+	t.logf(TRACE, format, v...)
+}
+
+func (t *TagLogger) Traceln(v ...interface{}) {
+	t.logln(TRACE, v...)
+}
+
+func (t *TagLogger) Debugf(format string, v ...interface{}) {
+	t.logf(DEBUG, format, v...)
+}
+
+func (t *TagLogger) Debugln(v ...interface{}) {
+	t.logln(DEBUG, v...)
+}
+
+func (t *TagLogger) Infof(format string, v ...interface{}) {
+	t.logf(INFO, format, v...)
+}
+
+func (t *TagLogger) Infoln(v ...interface{}) {
+	t.logln(INFO, v...)
+}
+
+func (t *TagLogger) Warningf(format string, v ...interface{}) {
+	t.logf(WARNING, format, v...)
+}
+
+func (t *TagLogger) Warningln(v ...interface{}) {
+	t.logln(WARNING, v...)
+}
+
+func (t *TagLogger) Errorf(format string, v ...interface{}) {
+	t.logf(ERROR, format, v...)
+}
+
+func (t *TagLogger) Errorln(v ...interface{}) {
+	t.logln(ERROR, v...)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (t *TagLogger) Fatalf(format string, v ...interface{}) {
+	t.logf(FATAL, format, v...)
+	t.logger.Sync()
+	Exit(t.logger.fatalCode())
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
+func (t *TagLogger) Fatalln(v ...interface{}) {
+	t.logln(FATAL, v...)
+	t.logger.Sync()
+	Exit(t.logger.fatalCode())
+}
+
+/* 以VERBOSE级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Verbose(msg string, fields ...Field) {
+	t.logw(VERBOSE, msg, fields)
+}
+
+/* 以TRACE级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Trace(msg string, fields ...Field) {
+	t.logw(TRACE, msg, fields)
+}
+
+/* 以DEBUG级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Debug(msg string, fields ...Field) {
+	t.logw(DEBUG, msg, fields)
+}
+
+/* 以INFO级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Info(msg string, fields ...Field) {
+	t.logw(INFO, msg, fields)
+}
+
+/* 以WARNING级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Warning(msg string, fields ...Field) {
+	t.logw(WARNING, msg, fields)
+}
+
+/* 以ERROR级别输出日志，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Error(msg string, fields ...Field) {
+	t.logw(ERROR, msg, fields)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程，msg原样输出，不会被当作格式串 */
+func (t *TagLogger) Fatal(msg string, fields ...Field) {
+	t.logw(FATAL, msg, fields)
+	t.logger.Sync()
+	Exit(t.logger.fatalCode())
+}
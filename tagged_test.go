@@ -0,0 +1,51 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTagged(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(WARNING)
+	l.SetTagLevel(DEBUG, "scheduler")
+
+	l.Tagged("scheduler").Debugf("job %d queued", 7)
+	l.Tagged("worker").Infoln("hidden")
+	l.Tagged("worker").Logw(ERROR, "crashed", F("job", 7))
+
+	out := buf.String()
+	if !strings.Contains(out, "[scheduler: TestTagged] job 7 queued") || !strings.Contains(out, "[worker: TestTagged] crashed job=7") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if strings.Contains(out, "hidden") {
+		t.Fatalf("disabled tag written: %q", out)
+	}
+}
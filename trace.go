@@ -0,0 +1,99 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+var traceDepths sync.Map /* 各goroutine中Enter的嵌套层数，goroutine ID -> *int */
+
+/* 返回的函数被调用时输出自Timed调用以来经过的时间 */
+/* 用法：defer zlog.Timed(DEBUG, "rebuild index")() */
+func (l *Logger) Timed(level uint8, label string) func() {
+	return l.timed(level, label)
+}
+
+func (l *Logger) timed(level uint8, label string) func() {
+	if !l.mayLog(level) {
+		return func() {}
+	}
+
+	c := l.caller(2, 0)
+	if !l.enabledAt(level, c.pkg, c.file) {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		l.output(c.entry(level, label, F("elapsed", time.Since(start))))
+	}
+}
+
+/* 返回的函数被调用时输出自Timed调用以来经过的时间 */
+/* 用法：defer zlog.Timed(DEBUG, "rebuild index")() */
+func Timed(level uint8, label string) func() {
+	return std.timed(level, label)
+}
+
+/* 以DEBUG级别输出进入调用函数的日志，返回的函数输出退出日志及耗时，按嵌套层数缩进 */
+/* 用法：defer zlog.Enter()() */
+func (l *Logger) Enter() func() {
+	return l.enter()
+}
+
+func (l *Logger) enter() func() {
+	if !l.mayLog(DEBUG) {
+		return func() {}
+	}
+
+	c := l.caller(2, 0)
+	if !l.enabledAt(DEBUG, c.pkg, c.file) {
+		return func() {}
+	}
+
+	gid := goroutineID()
+	v, _ := traceDepths.LoadOrStore(gid, new(int))
+	depth := v.(*int)
+	indent := strings.Repeat("  ", *depth)
+	*depth++
+
+	name := lastPath(c.pkg) + "." + c.fn
+	l.output(c.entry(DEBUG, indent+"-> "+name))
+
+	start := time.Now()
+	return func() {
+		if *depth--; *depth == 0 {
+			traceDepths.Delete(gid)
+		}
+		l.output(c.entry(DEBUG, indent+"<- "+name+" (took "+time.Since(start).String()+")"))
+	}
+}
+
+/* 以DEBUG级别输出进入调用函数的日志，返回的函数输出退出日志及耗时，按嵌套层数缩进 */
+/* 用法：defer zlog.Enter()() */
+func Enter() func() {
+	return std.enter()
+}
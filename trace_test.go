@@ -0,0 +1,87 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimed(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	func() {
+		defer l.Timed(DEBUG, "rebuild index")()
+		time.Sleep(2 * time.Millisecond)
+	}()
+
+	if !regexp.MustCompile(`DEBUG.*rebuild index elapsed=\d+(\.\d+)?ms`).Match(buf.Bytes()) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.SetLevel(INFO)
+	l.Timed(DEBUG, "hidden")()
+	if buf.Len() != 0 {
+		t.Fatalf("disabled timing written: %q", buf.String())
+	}
+}
+
+func traceInner(l *Logger) {
+	defer l.Enter()()
+}
+
+func traceOuter(l *Logger) {
+	defer l.Enter()()
+	traceInner(l)
+}
+
+func TestEnter(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	traceOuter(l)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"] -> zlog.traceOuter", "]   -> zlog.traceInner", "]   <- zlog.traceInner (took ", "] <- zlog.traceOuter (took "}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d: %q does not contain %q", i, lines[i], w)
+		}
+	}
+
+	if _, ok := traceDepths.Load(goroutineID()); ok {
+		t.Error("trace depth not released")
+	}
+}
@@ -0,0 +1,91 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlog
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/* W3C Trace Context中traceparent携带的链路信息，不依赖具体的链路追踪SDK */
+type TraceContext struct {
+	TraceID  string /* 32位小写十六进制 */
+	ParentID string /* 16位小写十六进制，即上游span的ID */
+	Sampled  bool   /* 上游是否采样 */
+}
+
+var errTraceparent = errors.New("zlog: invalid traceparent")
+
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+/* 解析traceparent，如"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" */
+/* 版本00之外的值按规范只读取前四项，全零的ID及版本ff视为无效 */
+func ParseTraceparent(value string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || !isLowerHex(parts[0], 2) || parts[0] == "ff" || parts[0] == "00" && len(parts) != 4 {
+		return TraceContext{}, errTraceparent
+	}
+
+	traceID, parentID, flags := parts[1], parts[2], parts[3]
+	if !isLowerHex(traceID, 32) || !isLowerHex(parentID, 16) || !isLowerHex(flags, 2) {
+		return TraceContext{}, errTraceparent
+	}
+	if traceID == strings.Repeat("0", 32) || parentID == strings.Repeat("0", 16) {
+		return TraceContext{}, errTraceparent
+	}
+
+	bits, _ := strconv.ParseUint(flags, 16, 8)
+	return TraceContext{TraceID: traceID, ParentID: parentID, Sampled: bits&1 == 1}, nil
+}
+
+/* 从请求头traceparent读取链路信息，没有或无效时ok为false */
+func TraceFromRequest(r *http.Request) (TraceContext, bool) {
+	tc, err := ParseTraceparent(r.Header.Get("traceparent"))
+	return tc, err == nil
+}
+
+/* 返回trace_id及parent_id字段 */
+func (tc TraceContext) Fields() []Field {
+	return []Field{F("trace_id", tc.TraceID), F("parent_id", tc.ParentID)}
+}
+
+/* 返回版本00的traceparent，用于向下游传递 */
+func (tc TraceContext) String() string {
+	flags := "00"
+	if tc.Sampled {
+		flags = "01"
+	}
+	return "00-" + tc.TraceID + "-" + tc.ParentID + "-" + flags
+}
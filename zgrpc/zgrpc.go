@@ -0,0 +1,131 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zgrpc /* grpclog.LoggerV2适配，使gRPC内部日志通过zlog输出 */
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/atlaslee/zlog"
+)
+
+const Tag = "grpc" /* gRPC日志使用的标志，可通过zlog.SetTagLevel单独设置级别 */
+
+/* 实现google.golang.org/grpc/grpclog.LoggerV2，用法：grpclog.SetLoggerV2(zgrpc.New(nil)) */
+/* Fatal系列记录FATAL日志并写出缓冲的日志，由gRPC负责退出进程，zlog的退出处理函数不会执行 */
+type Logger struct {
+	logger *zlog.Logger
+}
+
+/* 返回输出到l的gRPC日志适配器，l为nil时使用zlog.Default() */
+func New(l *zlog.Logger) *Logger {
+	if l == nil {
+		l = zlog.Default()
+	}
+	return &Logger{logger: l}
+}
+
+/* 跳过本包及grpclog自身的调用层，返回gRPC中调用日志的函数名 */
+func caller() string {
+	callers := make([]uintptr, 16)
+	frames := runtime.CallersFrames(callers[:runtime.Callers(4, callers)])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "/grpclog.") || !more {
+			return frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+		}
+	}
+}
+
+func (g *Logger) log(level uint8, msg string) {
+	if g.logger.Enabled(level, Tag) {
+		g.logger.LogEntry(&zlog.Entry{Level: level, Tag: Tag, Func: caller(), Message: msg})
+	}
+}
+
+/* 将gRPC的verbosity映射为zlog级别：0为INFO，1为DEBUG，2为TRACE，更高为VERBOSE */
+func (g *Logger) V(l int) bool {
+	level := zlog.VERBOSE
+	switch {
+	case l <= 0:
+		level = zlog.INFO
+	case l == 1:
+		level = zlog.DEBUG
+	case l == 2:
+		level = zlog.TRACE
+	}
+	return g.logger.Enabled(level, Tag)
+}
+
+func (g *Logger) Info(args ...interface{}) {
+	g.log(zlog.INFO, fmt.Sprint(args...))
+}
+
+func (g *Logger) Infoln(args ...interface{}) {
+	g.log(zlog.INFO, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *Logger) Infof(format string, args ...interface{}) {
+	g.log(zlog.INFO, fmt.Sprintf(format, args...))
+}
+
+func (g *Logger) Warning(args ...interface{}) {
+	g.log(zlog.WARNING, fmt.Sprint(args...))
+}
+
+func (g *Logger) Warningln(args ...interface{}) {
+	g.log(zlog.WARNING, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *Logger) Warningf(format string, args ...interface{}) {
+	g.log(zlog.WARNING, fmt.Sprintf(format, args...))
+}
+
+func (g *Logger) Error(args ...interface{}) {
+	g.log(zlog.ERROR, fmt.Sprint(args...))
+}
+
+func (g *Logger) Errorln(args ...interface{}) {
+	g.log(zlog.ERROR, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (g *Logger) Errorf(format string, args ...interface{}) {
+	g.log(zlog.ERROR, fmt.Sprintf(format, args...))
+}
+
+/* grpclog随后直接调用os.Exit，须先写出异步及缓冲输出目标中的日志，否则FATAL日志本身也会丢失 */
+func (g *Logger) Fatal(args ...interface{}) {
+	g.log(zlog.FATAL, fmt.Sprint(args...))
+	g.logger.Sync()
+}
+
+func (g *Logger) Fatalln(args ...interface{}) {
+	g.log(zlog.FATAL, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	g.logger.Sync()
+}
+
+func (g *Logger) Fatalf(format string, args ...interface{}) {
+	g.log(zlog.FATAL, fmt.Sprintf(format, args...))
+	g.logger.Sync()
+}
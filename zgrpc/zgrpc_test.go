@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zgrpc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atlaslee/zlog"
+)
+
+/* google.golang.org/grpc/grpclog.LoggerV2的方法集 */
+type loggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+}
+
+var _ loggerV2 = (*Logger)(nil)
+
+func TestGRPC(t *testing.T) {
+	var buf bytes.Buffer
+	l := zlog.NewLogger()
+	l.SetOutput(&buf)
+	l.SetTagLevel(zlog.WARNING, Tag)
+
+	g := New(l)
+	g.Infof("[core] channel %d created", 1)
+	g.Warningln("[transport] closing", "conn")
+	if g.V(2) || g.V(0) {
+		t.Error("unexpected verbosity")
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "channel") || !strings.Contains(out, "[grpc: TestGRPC] [transport] closing conn") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestGRPCFatalSyncs(t *testing.T) {
+	var buf bytes.Buffer
+	l := zlog.NewLogger()
+	l.SetOutput(zlog.NewBufferedWriter(&buf, 4096, time.Hour))
+
+	New(l).Fatalf("listen: %s", "address in use")
+	if !strings.Contains(buf.String(), "listen: address in use") {
+		t.Errorf("FATAL entry still buffered: %q", buf.String())
+	}
+}
@@ -23,31 +23,65 @@ DEALINGS IN THE SOFTWARE.
 package zlog /* 格式化日志工具 */
 
 import (
+	"errors"
 	"fmt"
-	"log"
-	"runtime"
+	"io"
+	"runtime/debug"
 	"strings"
-	"sync"
+	"sync/atomic"
 )
 
+/* 内置级别之间留有间隔，可通过RegisterLevel注册自定义级别，如 NOTICE = INFO + 5 */
 const (
-	VERBOSE uint8 = iota /* 最详细的输出 */
-	TRACE                /* 调试信息 */
-	DEBUG                /* 函数进入退出信息 */
-	INFO                 /* 服务启动关闭信息及请求访问信息 */
-	WARNING              /* 异常警告信息(无需人工干预) */
-	ERROR                /* 错误信息(不影响服务，需要人工干预) */
-	FATAL                /* 崩溃信息(无法继续提供服务) */
+	VERBOSE uint8 = 10 * iota /* 最详细的输出 */
+	TRACE                     /* 调试信息 */
+	DEBUG                     /* 函数进入退出信息 */
+	INFO                      /* 服务启动关闭信息及请求访问信息 */
+	WARNING                   /* 异常警告信息(无需人工干预) */
+	ERROR                     /* 错误信息(不影响服务，需要人工干预) */
+	FATAL                     /* 崩溃信息(无法继续提供服务) */
 	SILENCE
 )
 
-var LogLevelNames [8]string = [8]string{"VERBOSE", "TRACE", "DEBUG", "INFO", "WARNING", "ERROR", "FATAL", "SILENCE"}
+/* 以级别为下标的级别名称，未注册的级别为空字符串 */
+var LogLevelNames [256]string = [256]string{
+	VERBOSE: "VERBOSE",
+	TRACE:   "TRACE",
+	DEBUG:   "DEBUG",
+	INFO:    "INFO",
+	WARNING: "WARNING",
+	ERROR:   "ERROR",
+	FATAL:   "FATAL",
+	SILENCE: "SILENCE",
+}
 
-var (
-	globalLevel  uint8            = VERBOSE                /* 全局日志级别 */
-	loggerLevels map[string]uint8 = make(map[string]uint8) /* 指定标志日志级别 */
-	mu           sync.Mutex                                /* 全局锁，保证zlog线程安全 */
-)
+/* 以级别为下标的控制台颜色(ANSI SGR参数)，为空时不着色 */
+var levelColors [256]string = [256]string{
+	VERBOSE: "1;32",
+	TRACE:   "1;32",
+	DEBUG:   "1;32",
+	INFO:    "1;37",
+	WARNING: "1;37",
+	ERROR:   "1;31",
+	FATAL:   "1;31",
+}
+
+/* 注册自定义级别及其名称和颜色(如"1;33")，应在初始化阶段调用 */
+func RegisterLevel(level uint8, name, color string) error {
+	if name == "" {
+		return errors.New("zlog: empty level name")
+	}
+
+	if LogLevelNames[level] != "" {
+		return fmt.Errorf("zlog: level %d already registered as %s", level, LogLevelNames[level])
+	}
+
+	LogLevelNames[level] = name
+	levelColors[level] = color
+	return nil
+}
+
+var std = NewLogger() /* 包级函数使用的默认日志记录器 */
 
 func lastPath(str string) string {
 	peices := strings.Split(str, "/")
@@ -58,136 +92,312 @@ func lastPath(str string) string {
 	return peices[len(peices)-1]
 }
 
-/* 设置全局日志输出级别，低于该级别的日志不会输出 */
-func SetLevel(level uint8) {
-	globalLevel = level
+var trimPrefixes atomic.Value /* 推导标志前从函数全名中去掉的前缀，[]string */
+
+/* 推导标志时去掉函数全名中的前缀，如"github.com/fpay/fpay/"，使标志由github/com/fpay/fpay/p2p变为p2p */
+/* 影响所有Logger的标志，SetTagLevel等应使用去掉前缀后的标志，应在初始化阶段调用 */
+func SetTrimPrefixes(prefixes ...string) {
+	trimPrefixes.Store(append([]string(nil), prefixes...))
+	clearCallerCache()
 }
 
-/* 指定具体标志的日志级别，应小于全局级别 */
-/* 结合SetLevel，可以只输出指定标志的日志 */
-func SetTagLevel(level uint8, tags ...string) {
-	mu.Lock()
-	for _, tag := range tags {
-		loggerLevels[tag] = level
+/* 推导标志时去掉主模块的路径前缀，无法读取构建信息时返回false */
+func TrimModulePrefix() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path == "" {
+		return false
 	}
-	mu.Unlock()
-}
-
-func logf(level uint8, format string, v ...interface{}) {
-	callers := make([]uintptr, 1)
-	runtime.Callers(5, callers)
-	caller := runtime.FuncForPC(callers[0])
-	peices := strings.Split(caller.Name(), ".")
-	size := len(peices)
-	pkg := strings.Join(peices[:size-1], "/")
-	tagLevel, ok := loggerLevels[pkg]
-	if !ok {
-		tagLevel = globalLevel
+
+	SetTrimPrefixes(info.Main.Path + "/")
+	return true
+}
+
+func trimPackage(function string) string {
+	prefixes, _ := trimPrefixes.Load().([]string)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(function, prefix) {
+			return function[len(prefix):]
+		}
 	}
+	return function
+}
 
-	if level >= tagLevel {
-		method := peices[size-1]
-		switch level {
-		case VERBOSE, TRACE, DEBUG:
-			log.Printf(fmt.Sprintf("[%c[1;32m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintf(format, v...)))
-		case INFO, WARNING:
-			log.Printf(fmt.Sprintf("[%c[1;37m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintf(format, v...)))
-		case ERROR, FATAL:
-			log.Printf(fmt.Sprintf("[%c[1;31m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintf(format, v...)))
+/* 延迟求值的参数，只有在日志确定输出时才会被调用 */
+/* 例如 zlog.Debugln(zlog.Lazy(func() string { return Dump(obj) })) */
+type Lazy func() string
+
+/* 将Lazy及func() string类型的参数替换为其求值结果 */
+func resolve(v []interface{}) []interface{} {
+	var resolved []interface{}
+	for i, arg := range v {
+		var fn func() string
+		switch f := arg.(type) {
+		case Lazy:
+			fn = f
+		case func() string:
+			fn = f
 		default:
-			log.Printf(fmt.Sprintf("[%s][%s: %s] %s", LogLevelNames[level], lastPath(peices[size-2]), method, fmt.Sprintf(format, v...)))
+			continue
+		}
+
+		if resolved == nil {
+			resolved = make([]interface{}, len(v))
+			copy(resolved, v)
 		}
+		resolved[i] = fn()
 	}
+
+	if resolved == nil {
+		return v
+	}
+	return resolved
 }
 
-func logln(level uint8, v ...interface{}) {
-	callers := make([]uintptr, 1)
-	runtime.Callers(5, callers)
-	caller := runtime.FuncForPC(callers[0])
-	peices := strings.Split(caller.Name(), ".")
-	size := len(peices)
-	pkg := strings.Join(peices[:size-1], "/")
+var wrapVerbs = strings.NewReplacer("%%", "%%", "%w", "%v") /* 将%w替换为%v，保留转义的%% */
 
-	tagLevel, ok := loggerLevels[pkg]
-	if !ok {
-		tagLevel = globalLevel
+/* 格式化日志内容，没有参数时format原样作为内容，避免其中的%被当作格式动词 */
+/* format包含%w时按%v格式化，并将被包装的错误作为error字段返回 */
+func sprintf(format string, v []interface{}) (string, []Field) {
+	if len(v) == 0 {
+		return format, nil
 	}
 
-	if level >= tagLevel {
-		method := peices[size-1]
-		switch level {
-		case VERBOSE, TRACE, DEBUG:
-			log.Printf(fmt.Sprintf("[%c[1;32m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintln(v...)))
-		case INFO, WARNING:
-			log.Printf(fmt.Sprintf("[%c[1;37m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintln(v...)))
-		case ERROR, FATAL:
-			log.Printf(fmt.Sprintf("[%c[1;31m%s%c[0m][%s: %s] %s", 0x1B, LogLevelNames[level], 0x1B, lastPath(peices[size-2]), method, fmt.Sprintln(v...)))
-		default:
-			log.Printf(fmt.Sprintf("[%s][%s: %s] %s", LogLevelNames[level], lastPath(peices[size-2]), method, fmt.Sprintln(v...)))
+	v = resolve(v)
+	if !strings.Contains(format, "%w") {
+		return fmt.Sprintf(format, v...), nil
+	}
+
+	msg := fmt.Sprintf(wrapVerbs.Replace(format), v...)
+	switch u := fmt.Errorf(format, v...).(type) {
+	case interface{ Unwrap() error }:
+		if wrapped := u.Unwrap(); wrapped != nil {
+			return msg, []Field{Err(wrapped)}
+		}
+	case interface{ Unwrap() []error }:
+		if wrapped := u.Unwrap(); len(wrapped) == 1 {
+			return msg, []Field{Err(wrapped[0])}
+		} else if len(wrapped) > 1 {
+			return msg, []Field{Err(errors.Join(wrapped...))}
 		}
 	}
+	return msg, nil
+}
+
+/* 返回包级函数使用的默认日志记录器 */
+func Default() *Logger {
+	return std
+}
+
+/* 设置全局日志输出级别，低于该级别的日志不会输出 */
+func SetLevel(level uint8) {
+	std.SetLevel(level)
+}
+
+/* 指定具体标志的日志级别，应小于全局级别 */
+/* 结合SetLevel，可以只输出指定标志的日志 */
+func SetTagLevel(level uint8, tags ...string) {
+	std.SetTagLevel(level, tags...)
+}
+
+/* 指定源文件的日志级别，优先于标志级别 */
+func SetFileLevel(level uint8, files ...string) {
+	std.SetFileLevel(level, files...)
+}
+
+/* 设置默认日志记录器的输出目标 */
+func SetOutput(w io.Writer) {
+	std.SetOutput(w)
+}
+
+/* 设置默认日志记录器ERROR及以上级别日志的输出目标 */
+func SetErrorOutput(w io.Writer) {
+	std.SetErrorOutput(w)
+}
+
+/* 默认日志记录器的ERROR及以上级别输出到标准错误，其他级别输出到标准输出 */
+func SplitOutput() {
+	std.SplitOutput()
+}
+
+/* 设置默认日志记录器的日志格式 */
+func SetFormatter(f Formatter) {
+	std.SetFormatter(f)
+}
+
+/* 默认日志记录器丢弃内容匹配任一正则的日志 */
+func DropMatching(exprs ...string) error {
+	return std.DropMatching(exprs...)
+}
+
+/* 默认日志记录器只保留内容匹配任一正则的日志 */
+func KeepMatching(exprs ...string) error {
+	return std.KeepMatching(exprs...)
+}
+
+/* 为默认日志记录器添加自定义过滤规则 */
+func AddFilter(filters ...Filter) {
+	std.AddFilter(filters...)
+}
+
+/* 清除默认日志记录器的内容过滤规则及自定义过滤规则 */
+func ClearFilters() {
+	std.ClearFilters()
+}
+
+/* 默认日志记录器对指定键名的值脱敏 */
+func RedactKeys(keys ...string) {
+	std.RedactKeys(keys...)
+}
+
+/* 默认日志记录器将匹配任一正则的内容脱敏 */
+func RedactPatterns(exprs ...string) error {
+	return std.RedactPatterns(exprs...)
+}
+
+/* 写出并落盘默认日志记录器及审计日志中缓冲的日志，应在进程退出前调用 */
+func Flush() error {
+	err := std.Sync()
+	if aerr := auditor.Sync(); err == nil {
+		err = aerr
+	}
+	return err
+}
+
+/* 关闭默认日志记录器及审计日志，适合在main()中defer调用 */
+func Close() error {
+	err := std.Close()
+	if aerr := auditor.Close(); err == nil {
+		err = aerr
+	}
+	return err
+}
+
+/* 判断调用处以指定级别记录的日志是否会输出，用于避免无谓的预先格式化 */
+func IsEnabled(level uint8) bool {
+	c := std.caller(1, 0)
+	return std.enabledAt(level, c.pkg, c.file)
 }
 
 func Logf(level uint8, format string, v ...interface{}) {
-	logf(level, format, v...)
+	std.logf(0, level, format, v...)
 }
 
 func Logln(level uint8, v ...interface{}) {
-	logln(level, v...)
+	std.logln(0, level, v...)
+}
+
+/* 与Logf相同，解析调用者时额外跳过depth层，用于一次性的封装调用 */
+func LogfDepth(depth int, level uint8, format string, v ...interface{}) {
+	std.logf(depth, level, format, v...)
+}
+
+/* 与Logln相同，解析调用者时额外跳过depth层，用于一次性的封装调用 */
+func LoglnDepth(depth int, level uint8, v ...interface{}) {
+	std.logln(depth, level, v...)
+}
+
+/* 输出带结构化字段的日志，msg不会被当作格式串 */
+func Logw(level uint8, msg string, fields ...Field) {
+	std.logw(0, level, msg, fields)
+}
+
+/* 以ERROR级别输出日志及err的错误链 */
+func Errorw(msg string, err error, fields ...Field) {
+	std.logw(0, ERROR, msg, append([]Field{Err(err)}, fields...))
 }
 
 func Verbosef(format string, v ...interface{}) {
-	Logf(VERBOSE, format, v...)
+	std.logf(0, VERBOSE, format, v...)
 }
 
 func Verboseln(v ...interface{}) {
-	Logln(VERBOSE, v...)
+	std.logln(0, VERBOSE, v...)
 }
 
 func Tracef(format string, v ...interface{}) {
-	Logf(TRACE, format, v...)
+	std.logf(0, TRACE, format, v...)
 }
 
 func Traceln(v ...interface{}) {
-	Logln(TRACE, v...)
+	std.logln(0, TRACE, v...)
 }
 
 func Debugf(format string, v ...interface{}) {
-	Logf(DEBUG, format, v...)
+	std.logf(0, DEBUG, format, v...)
 }
 
 func Debugln(v ...interface{}) {
-	Logln(DEBUG, v...)
+	std.logln(0, DEBUG, v...)
 }
 
 func Infof(format string, v ...interface{}) {
-	Logf(INFO, format, v...)
+	std.logf(0, INFO, format, v...)
 }
 
 func Infoln(v ...interface{}) {
-	Logln(INFO, v...)
+	std.logln(0, INFO, v...)
 }
 
 func Warningf(format string, v ...interface{}) {
-	Logf(WARNING, format, v...)
+	std.logf(0, WARNING, format, v...)
 }
 
 func Warningln(v ...interface{}) {
-	Logln(WARNING, v...)
+	std.logln(0, WARNING, v...)
 }
 
 func Errorf(format string, v ...interface{}) {
-	Logf(ERROR, format, v...)
+	std.logf(0, ERROR, format, v...)
 }
 
 func Errorln(v ...interface{}) {
-	Logln(ERROR, v...)
+	std.logln(0, ERROR, v...)
 }
 
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
 func Fatalf(format string, v ...interface{}) {
-	Logf(FATAL, format, v...)
+	std.logf(0, FATAL, format, v...)
+	Exit(std.fatalCode())
 }
 
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程 */
 func Fatalln(v ...interface{}) {
-	Logln(FATAL, v...)
+	std.logln(0, FATAL, v...)
+	Exit(std.fatalCode())
+}
+
+/* 以VERBOSE级别输出日志，msg原样输出，不会被当作格式串 */
+func Verbose(msg string, fields ...Field) {
+	std.logw(0, VERBOSE, msg, fields)
+}
+
+/* 以TRACE级别输出日志，msg原样输出，不会被当作格式串 */
+func Trace(msg string, fields ...Field) {
+	std.logw(0, TRACE, msg, fields)
+}
+
+/* 以DEBUG级别输出日志，msg原样输出，不会被当作格式串 */
+func Debug(msg string, fields ...Field) {
+	std.logw(0, DEBUG, msg, fields)
+}
+
+/* 以INFO级别输出日志，msg原样输出，不会被当作格式串 */
+func Info(msg string, fields ...Field) {
+	std.logw(0, INFO, msg, fields)
+}
+
+/* 以WARNING级别输出日志，msg原样输出，不会被当作格式串 */
+func Warning(msg string, fields ...Field) {
+	std.logw(0, WARNING, msg, fields)
+}
+
+/* 以ERROR级别输出日志，msg原样输出，不会被当作格式串 */
+func Error(msg string, fields ...Field) {
+	std.logw(0, ERROR, msg, fields)
+}
+
+/* 输出FATAL日志后执行退出处理函数并以SetExitCode设置的退出码退出进程，msg原样输出，不会被当作格式串 */
+func Fatal(msg string, fields ...Field) {
+	std.logw(0, FATAL, msg, fields)
+	Exit(std.fatalCode())
 }
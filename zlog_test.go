@@ -25,9 +25,526 @@ SOFTWARE.
 package zlog
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestZLog(t *testing.T) {
 	Infoln("hello world")
 }
+
+func TestLazy(t *testing.T) {
+	called := false
+	SetLevel(INFO)
+	defer SetLevel(VERBOSE)
+
+	Debugln(Lazy(func() string {
+		called = true
+		return "expensive"
+	}))
+	if called {
+		t.Fatal("lazy argument evaluated for disabled level")
+	}
+
+	Infof("%s %s", Lazy(func() string {
+		called = true
+		return "lazy"
+	}), func() string { return "func" })
+	if !called {
+		t.Fatal("lazy argument not evaluated for enabled level")
+	}
+}
+
+func TestVerbatimMessage(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true})
+
+	input := "GET /search?q=100%25&n=%d"
+	l.Info(input)
+	l.Error("disk 100% full", F("mount", "/var"))
+	l.Infof(input)
+	l.Tagged("api").Warning(input)
+
+	out := buf.String()
+	if strings.Contains(out, "%!") {
+		t.Fatalf("message interpreted as format: %q", out)
+	}
+	for _, want := range []string{
+		"[INFO][zlog: TestVerbatimMessage] " + input + "\n",
+		"[ERROR][zlog: TestVerbatimMessage] disk 100% full mount=/var\n",
+		"[WARNING][api: TestVerbatimMessage] " + input + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	if n := strings.Count(out, input); n != 3 {
+		t.Errorf("got %d verbatim messages, want 3: %q", n, out)
+	}
+}
+
+func TestWrapVerb(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&JSONFormatter{})
+
+	cause := errors.New("connection refused")
+	format := "saving user %d: %w" /* 非常量格式串，避免vet对%w报错 */
+	l.Errorf(format, 42, cause)
+	l.Errorf("%d%%w done", 100) /* 转义的%w不被替换 */
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"message":"100%w done"`) {
+		t.Fatalf("unexpected output %q", lines)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["message"] != "saving user 42: connection refused" {
+		t.Errorf("message = %q", got["message"])
+	}
+	fields, _ := got["fields"].(map[string]interface{})
+	if fields["error"] != "connection refused" {
+		t.Errorf("error field = %v, want the wrapped error", got["fields"])
+	}
+
+	buf.Reset()
+	l.SetFormatter(&TextFormatter{NoColor: true})
+	l.Errorf(format, 7, nil)
+	if out := buf.String(); strings.Contains(out, "error=") || !strings.Contains(out, "saving user 7: <nil>\n") {
+		t.Errorf("unexpected output for nil error: %q", out)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	l := NewLogger()
+	l.SetLevel(WARNING)
+	l.SetTagLevel(DEBUG, "fpay/p2p")
+
+	if l.Enabled(INFO, "fpay/db") {
+		t.Error("INFO should be disabled by global level")
+	}
+	if !l.Enabled(ERROR, "fpay/db") {
+		t.Error("ERROR should be enabled by global level")
+	}
+	if !l.Enabled(DEBUG, "fpay/p2p") {
+		t.Error("DEBUG should be enabled by tag level")
+	}
+
+	SetLevel(SILENCE)
+	defer SetLevel(VERBOSE)
+	if IsEnabled(FATAL) {
+		t.Error("FATAL should be disabled at SILENCE")
+	}
+}
+
+func TestSync(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(bufio.NewWriter(&buf))
+
+	l.Infoln("buffered")
+	if buf.Len() != 0 {
+		t.Fatal("entry written before Sync")
+	}
+
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "buffered") {
+		t.Fatalf("entry missing after Sync: %q", buf.String())
+	}
+}
+
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	var buf closeBuffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	l.Infoln("before close")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !buf.closed {
+		t.Fatal("output not closed")
+	}
+
+	l.Infoln("after close")
+	if strings.Contains(buf.String(), "after close") {
+		t.Fatal("entry written after Close")
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Infof("request %d served", 42)
+		}
+	})
+}
+
+func TestRegisterLevel(t *testing.T) {
+	const NOTICE = INFO + 5
+	t.Cleanup(func() {
+		LogLevelNames[NOTICE], levelColors[NOTICE] = "", ""
+	})
+	if err := RegisterLevel(NOTICE, "NOTICE", "1;36"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterLevel(NOTICE, "NOTICE", ""); err == nil {
+		t.Fatal("duplicate level registered")
+	}
+
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(NOTICE)
+
+	l.Infoln("hidden")
+	l.Logln(NOTICE, "shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "NOTICE") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestLevelNames(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{LevelNames: map[uint8]string{INFO: "INF"}})
+
+	l.Infoln("short")
+	l.Warningln("long")
+	if !strings.Contains(buf.String(), "INF\x1b") || !strings.Contains(buf.String(), "WARNING") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestLevelSymbols(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true, Symbols: true})
+	l.Warningln("low disk")
+	l.SetFormatter(&TextFormatter{NoColor: true, SymbolOnly: true})
+	l.Errorln("failed")
+
+	out := buf.String()
+	if !strings.Contains(out, "[⚠ WARNING][zlog: TestLevelSymbols] low disk") || !strings.Contains(out, "[✖][zlog: TestLevelSymbols] failed") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestMultiline(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	l.SetFormatter(&TextFormatter{NoColor: true, Multiline: MultilineIndent})
+	l.Infoln("SELECT *\nFROM t")
+	if !strings.HasSuffix(buf.String(), "] SELECT *\n\tFROM t\n") {
+		t.Errorf("indent: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.SetFormatter(&TextFormatter{NoColor: true, Multiline: MultilineEscape})
+	l.Logw(INFO, "a\nb", F("sql", "x\ny"))
+	if out := buf.String(); strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, `] a\nb sql="x\ny"`+"\n") {
+		t.Errorf("escape: %q", out)
+	}
+}
+
+func TestMessageFilters(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	if err := l.DropMatching(`^deprecated API`); err != nil {
+		t.Fatal(err)
+	}
+	l.Warningln("deprecated API v1 called")
+	l.Warningln("disk almost full")
+	if strings.Contains(buf.String(), "deprecated") || !strings.Contains(buf.String(), "disk") {
+		t.Fatalf("drop filter: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.ClearFilters()
+	if err := l.KeepMatching(`peer`); err != nil {
+		t.Fatal(err)
+	}
+	l.Infoln("peer connected")
+	l.Infoln("block imported")
+	if !strings.Contains(buf.String(), "peer") || strings.Contains(buf.String(), "block") {
+		t.Fatalf("keep filter: %q", buf.String())
+	}
+
+	if err := l.DropMatching(`(`); err == nil {
+		t.Fatal("invalid regexp accepted")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.RedactKeys("password", "Authorization")
+	if err := l.RedactPatterns(`\b\d{4}-?\d{4}-?\d{4}-?\d{4}\b`); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Infof("login user=atlas password=%s authorization: Bearer abc123 card %s", "s3cret", "4111-1111-1111-1111")
+	out := buf.String()
+	for _, leaked := range []string{"s3cret", "abc123", "4111"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("%q leaked: %q", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "user=atlas password=***") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	var out, errOut bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&out)
+	l.SetErrorOutput(&errOut)
+
+	l.Warningln("to stdout")
+	l.Errorln("to stderr")
+	if !strings.Contains(out.String(), "to stdout") || strings.Contains(out.String(), "to stderr") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "to stderr") || strings.Contains(errOut.String(), "to stdout") {
+		t.Fatalf("unexpected error output: %q", errOut.String())
+	}
+}
+
+func TestNop(t *testing.T) {
+	l := Nop()
+	if l.Enabled(FATAL, "fpay/p2p") {
+		t.Fatal("nop logger enabled")
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { l.Debugf("peer %s", "a") }); allocs > 1 {
+		t.Fatalf("nop logger allocates %v per call", allocs)
+	}
+}
+
+func TestConcurrentLevels(t *testing.T) {
+	l := NewLogger()
+	l.SetOutput(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				l.Debugf("tick %d", j)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.SetLevel(uint8(j % 2 * 30))
+				l.SetTagLevel(INFO, fmt.Sprintf("tag%d", i))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestGoroutineID(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{Goroutine: true})
+
+	l.Infoln("main")
+	done := make(chan struct{})
+	go func() {
+		l.Infoln("worker")
+		close(done)
+	}()
+	<-done
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], fmt.Sprintf("][g%d][", goroutineID())) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+	if strings.Contains(lines[1], fmt.Sprintf("][g%d][", goroutineID())) || !strings.Contains(lines[1], "][g") {
+		t.Fatalf("worker goroutine not distinguished: %q", lines[1])
+	}
+}
+
+func wrappedInfo(l *Logger, msg string) {
+	l.Infoln(msg)
+}
+
+func wrappedDepth(l *Logger, msg string) {
+	l.LoglnDepth(1, INFO, msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+
+	l.Infoln("direct")
+	wrappedDepth(l, "depth")
+	l.AddCallerSkip(1)
+	wrappedInfo(l, "wrapped")
+
+	out := buf.String()
+	for _, want := range []string{"[zlog: TestCallerSkip] direct", "[zlog: TestCallerSkip] depth", "[zlog: TestCallerSkip] wrapped"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+}
+
+func TestTagLevelPrefix(t *testing.T) {
+	l := NewLogger()
+	l.SetLevel(WARNING)
+	l.SetTagLevel(DEBUG, "fpay")
+	l.SetTagLevel(ERROR, "fpay/p2p")
+
+	for _, c := range []struct {
+		tag  string
+		want bool
+	}{
+		{"fpay", true},
+		{"fpay/db", true},
+		{"fpay/db/sql", true},
+		{"fpay/p2p", false},
+		{"fpay/p2p/gossip", false},
+		{"fpaygate", false},
+		{"other", false},
+	} {
+		if got := l.Enabled(DEBUG, c.tag); got != c.want {
+			t.Errorf("Enabled(DEBUG, %q) = %v, want %v", c.tag, got, c.want)
+		}
+	}
+}
+
+func TestFileLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(WARNING)
+	l.SetTagLevel(ERROR, "github/com/atlaslee/zlog")
+
+	l.Debugln("before")
+	l.SetFileLevel(DEBUG, "zlog_test.go")
+	l.Debugln("after")
+	l.Tagged("p2p").Debugln("tagged")
+	l.Traceln("trace")
+
+	out := buf.String()
+	for _, want := range []string{"after", "tagged"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	for _, unwanted := range []string{"before", "trace"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in %q", unwanted, out)
+		}
+	}
+
+	if !l.enabledAt(DEBUG, "other", "/src/fpay/zlog_test.go") || l.enabledAt(DEBUG, "other", "/src/fpay/xzlog_test.go") {
+		t.Error("file level should match whole path components only")
+	}
+
+	/* 最长的后缀优先，与map的遍历顺序无关 */
+	l.SetFileLevel(ERROR, "consensus.go")
+	l.SetFileLevel(DEBUG, "fpay/consensus/consensus.go")
+	for i := 0; i < 20; i++ {
+		if !l.enabledAt(DEBUG, "other", "/src/fpay/consensus/consensus.go") || l.enabledAt(WARNING, "other", "/src/p2p/consensus.go") {
+			t.Fatal("longest file suffix should win")
+		}
+	}
+}
+
+func TestTrimPrefixes(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&TextFormatter{NoColor: true, FullTag: true})
+
+	l.Infoln("full")
+	SetTrimPrefixes("github.com/")
+	defer SetTrimPrefixes()
+	l.SetLevel(INFO)
+	l.SetTagLevel(DEBUG, "atlaslee/zlog")
+	l.Debugln("trimmed")
+
+	out := buf.String()
+	for _, want := range []string{"[github/com/atlaslee/zlog: TestTrimPrefixes] full", "[atlaslee/zlog: TestTrimPrefixes] trimmed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+}
+
+func TestCallerFormat(t *testing.T) {
+	e := newEntry(INFO, "github/com/fpay/fpay/p2p", "Dial", "msg")
+	e.File, e.Line = "/src/fpay/p2p/dial.go", 42
+
+	for format, want := range map[CallerFormat]string{
+		CallerTagFunc: "[p2p: Dial]",
+		CallerTag:     "[p2p]",
+		CallerDotFunc: "[p2p.Dial]",
+		CallerFile:    "[p2p/dial.go:42]",
+		CallerFull:    "[github/com/fpay/fpay/p2p.Dial]",
+	} {
+		var buf bytes.Buffer
+		(&TextFormatter{NoColor: true, Caller: format}).Format(&buf, e)
+		if !strings.Contains(buf.String(), want+" msg") {
+			t.Errorf("format %d: %q, want %q", format, buf.String(), want)
+		}
+	}
+}
+
+func TestCallerCache(t *testing.T) {
+	defer SetTrimPrefixes()
+
+	var tags []string
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			SetTrimPrefixes("github.com/atlaslee/")
+		}
+		tags = append(tags, caller(0).pkg)
+	}
+
+	if tags[0] != "github/com/atlaslee/zlog" || tags[1] != tags[0] || tags[2] != "zlog" {
+		t.Errorf("tags = %v", tags)
+	}
+}
@@ -0,0 +1,145 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlogr /* logr.LogSink适配，使基于logr的库通过zlog输出日志 */
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/atlaslee/zlog"
+	"github.com/go-logr/logr"
+)
+
+const defaultTag = "logr" /* 未调用WithName时使用的标志 */
+
+/* 基于zlog.Logger的logr.LogSink */
+type sink struct {
+	logger *zlog.Logger
+	name   string       /* WithName累积的名称，以/连接，用作zlog标志 */
+	values []zlog.Field /* WithValues累积的字段 */
+	depth  int          /* logr及WithCallDepth增加的调用层数 */
+}
+
+/* 返回输出到l的logr.Logger，l为nil时使用zlog.Default() */
+func New(l *zlog.Logger) logr.Logger {
+	return logr.New(NewSink(l))
+}
+
+/* 返回输出到l的logr.LogSink，l为nil时使用zlog.Default() */
+func NewSink(l *zlog.Logger) logr.LogSink {
+	if l == nil {
+		l = zlog.Default()
+	}
+	return &sink{logger: l}
+}
+
+/* 将logr的V级别映射为zlog级别：0为INFO，1为DEBUG，2为TRACE，更高为VERBOSE */
+func level(v int) uint8 {
+	switch {
+	case v <= 0:
+		return zlog.INFO
+	case v == 1:
+		return zlog.DEBUG
+	case v == 2:
+		return zlog.TRACE
+	default:
+		return zlog.VERBOSE
+	}
+}
+
+func toFields(keysAndValues []interface{}) []zlog.Field {
+	fields := make([]zlog.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "<no-value>"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields = append(fields, zlog.F(fmt.Sprint(keysAndValues[i]), value))
+	}
+	return fields
+}
+
+func (s *sink) tag() string {
+	if s.name == "" {
+		return defaultTag
+	}
+	return s.name
+}
+
+/* 解析logr调用者的函数名 */
+func (s *sink) caller() string {
+	callers := make([]uintptr, 1)
+	n := runtime.Callers(s.depth+4, callers)
+	frame, _ := runtime.CallersFrames(callers[:n]).Next()
+	return frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+}
+
+func (s *sink) log(level uint8, msg string, fields []zlog.Field, keysAndValues []interface{}) {
+	fields = append(append(fields, s.values...), toFields(keysAndValues)...)
+	s.logger.LogEntry(&zlog.Entry{
+		Level:   level,
+		Tag:     s.tag(),
+		Func:    s.caller(),
+		Message: msg,
+		Fields:  fields,
+	})
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+func (s *sink) Enabled(v int) bool {
+	return s.logger.Enabled(level(v), s.tag())
+}
+
+func (s *sink) Info(v int, msg string, keysAndValues ...interface{}) {
+	s.log(level(v), msg, nil, keysAndValues)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.log(zlog.ERROR, msg, []zlog.Field{zlog.Err(err)}, keysAndValues)
+}
+
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := *s
+	c.values = append(append([]zlog.Field{}, s.values...), toFields(keysAndValues)...)
+	return &c
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name == "" {
+		c.name = name
+	} else {
+		c.name += "/" + name
+	}
+	return &c
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.depth += depth
+	return &c
+}
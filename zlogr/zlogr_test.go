@@ -0,0 +1,61 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlogr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atlaslee/zlog"
+)
+
+func TestLogr(t *testing.T) {
+	var buf bytes.Buffer
+	l := zlog.NewLogger()
+	l.SetOutput(&buf)
+	l.SetLevel(zlog.DEBUG)
+
+	log := New(l).WithName("controller").WithValues("kind", "Pod")
+	log.Info("reconciling", "name", "web")
+	log.V(1).Info("cache hit")
+	log.V(2).Info("hidden")
+	log.Error(errors.New("conflict"), "update failed")
+
+	out := buf.String()
+	for _, want := range []string{
+		"[controller: TestLogr] reconciling kind=Pod name=web",
+		"DEBUG",
+		`update failed error=conflict kind=Pod`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("V(2) should be disabled at DEBUG: %q", out)
+	}
+}
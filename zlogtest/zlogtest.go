@@ -0,0 +1,139 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zlogtest /* 测试中捕获并断言zlog日志的工具 */
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atlaslee/zlog"
+)
+
+/* 捕获到的一条日志 */
+type ObservedEntry struct {
+	zlog.Entry
+}
+
+/* 捕获日志的zlog.Formatter，同时将日志交给原有格式输出 */
+type Observer struct {
+	mu      sync.Mutex
+	entries []ObservedEntry
+	next    zlog.Formatter /* 原有格式，为nil时不输出文本 */
+}
+
+/* 返回一个只捕获不输出的Logger及其Observer */
+func New() (*zlog.Logger, *Observer) {
+	l := zlog.NewLogger()
+	l.SetOutput(io.Discard)
+	o := &Observer{}
+	l.SetFormatter(o)
+	return l, o
+}
+
+/* 在测试期间捕获l的日志，l为nil时捕获zlog.Default()，测试结束后恢复原有格式 */
+func Observe(t testing.TB, l *zlog.Logger) *Observer {
+	if l == nil {
+		l = zlog.Default()
+	}
+
+	prev := l.Formatter()
+	o := &Observer{next: prev}
+	l.SetFormatter(o)
+	t.Cleanup(func() {
+		l.SetFormatter(prev)
+	})
+	return o
+}
+
+func (o *Observer) Format(buf *bytes.Buffer, e *zlog.Entry) {
+	entry := *e
+	entry.Fields = append([]zlog.Field(nil), e.Fields...)
+
+	o.mu.Lock()
+	o.entries = append(o.entries, ObservedEntry{entry})
+	o.mu.Unlock()
+
+	if o.next != nil {
+		o.next.Format(buf, e)
+	}
+}
+
+/* 返回捕获到的所有日志 */
+func (o *Observer) All() []ObservedEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]ObservedEntry(nil), o.entries...)
+}
+
+/* 返回指定级别的日志 */
+func (o *Observer) FilterLevel(level uint8) []ObservedEntry {
+	var res []ObservedEntry
+	for _, e := range o.All() {
+		if e.Level == level {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+/* 返回内容包含substring的日志 */
+func (o *Observer) FilterMessage(substring string) []ObservedEntry {
+	var res []ObservedEntry
+	for _, e := range o.All() {
+		if strings.Contains(e.Message, substring) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+/* 清空捕获到的日志 */
+func (o *Observer) Reset() {
+	o.mu.Lock()
+	o.entries = nil
+	o.mu.Unlock()
+}
+
+/* 断言捕获到了指定级别且内容包含substring的日志 */
+func (o *Observer) AssertLogged(t testing.TB, level uint8, substring string) {
+	t.Helper()
+	for _, e := range o.FilterLevel(level) {
+		if strings.Contains(e.Message, substring) {
+			return
+		}
+	}
+	t.Errorf("no %s entry containing %q, got %d entries", zlog.LogLevelNames[level], substring, len(o.All()))
+}
+
+/* 断言没有捕获到指定级别且内容包含substring的日志 */
+func (o *Observer) AssertNotLogged(t testing.TB, level uint8, substring string) {
+	t.Helper()
+	for _, e := range o.FilterLevel(level) {
+		if strings.Contains(e.Message, substring) {
+			t.Errorf("unexpected %s entry %q", zlog.LogLevelNames[level], e.Message)
+		}
+	}
+}
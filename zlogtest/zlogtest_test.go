@@ -0,0 +1,54 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zlogtest
+
+import (
+	"testing"
+
+	"github.com/atlaslee/zlog"
+)
+
+func TestObserver(t *testing.T) {
+	l, o := New()
+	l.Warningf("disk %d%% full", 91)
+	l.Logw(zlog.INFO, "started", zlog.F("port", 8080))
+
+	o.AssertLogged(t, zlog.WARNING, "91% full")
+	o.AssertNotLogged(t, zlog.ERROR, "full")
+	if entries := o.FilterMessage("started"); len(entries) != 1 || entries[0].Fields[0].Value != 8080 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	o.Reset()
+	if len(o.All()) != 0 {
+		t.Fatal("entries not reset")
+	}
+}
+
+func TestObserveDefault(t *testing.T) {
+	o := Observe(t, nil)
+	zlog.Errorln("connection refused")
+	o.AssertLogged(t, zlog.ERROR, "connection refused")
+}
@@ -0,0 +1,359 @@
+/* The MIT License (MIT)
+Copyright © 2018 by Atlas Lee(atlas@fpay.io)
+
+Permission is hereby granted, free of charge, to any person obtaining a
+copy of this software and associated documentation files (the “Software”),
+to deal in the Software without restriction, including without limitation
+the rights to use, copy, modify, merge, publish, distribute, sublicense,
+and/or sell copies of the Software, and to permit persons to whom the
+Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED “AS IS”, WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+DEALINGS IN THE SOFTWARE.
+*/
+
+package zsql /* database/sql驱动包装，记录每条SQL的语句、参数、影响行数及耗时 */
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/atlaslee/zlog"
+)
+
+const Tag = "sql" /* SQL日志使用的标志，可通过zlog.SetTagLevel单独设置级别 */
+
+/* 驱动包装的配置 */
+type Config struct {
+	Logger   *zlog.Logger  /* 输出目标，为nil时使用zlog.Default() */
+	Slow     time.Duration /* 耗时超过该值的语句以WARNING级别输出，为0时不判断 */
+	HideArgs bool          /* 不输出参数的值，只输出个数 */
+}
+
+/* 返回记录SQL日志的驱动，语句以DEBUG级别输出，慢语句及出错的语句以WARNING级别输出 */
+/* 用法：sql.Register("postgres-zlog", zsql.Wrap(&pq.Driver{}, zsql.Config{Slow: 200 * time.Millisecond})) */
+func Wrap(d driver.Driver, cfg Config) driver.Driver {
+	if cfg.Logger == nil {
+		cfg.Logger = zlog.Default()
+	}
+	return &wrappedDriver{d: d, cfg: &cfg}
+}
+
+/* 返回记录SQL日志的Connector，用于sql.OpenDB */
+func WrapConnector(c driver.Connector, cfg Config) driver.Connector {
+	if cfg.Logger == nil {
+		cfg.Logger = zlog.Default()
+	}
+	return &wrappedConnector{c: c, d: &wrappedDriver{d: c.Driver(), cfg: &cfg}}
+}
+
+type wrappedDriver struct {
+	d   driver.Driver
+	cfg *Config
+}
+
+func (w *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := w.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{conn: conn, cfg: w.cfg}, nil
+}
+
+type wrappedConnector struct {
+	c driver.Connector
+	d *wrappedDriver
+}
+
+func (w *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := w.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{conn: conn, cfg: w.d.cfg}, nil
+}
+
+func (w *wrappedConnector) Driver() driver.Driver {
+	return w.d
+}
+
+/* 输出一条语句的日志，err为driver.ErrSkip时不输出，database/sql将改为预编译后执行 */
+func (cfg *Config) log(op, query string, args []driver.NamedValue, start time.Time, result driver.Result, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+
+	latency := time.Since(start)
+	level := zlog.DEBUG
+	if err != nil || cfg.Slow > 0 && latency >= cfg.Slow {
+		level = zlog.WARNING
+	}
+	if !cfg.Logger.Enabled(level, Tag) {
+		return
+	}
+
+	fields := []zlog.Field{zlog.F("latency", latency)}
+	if cfg.HideArgs {
+		if len(args) > 0 {
+			fields = append(fields, zlog.F("args", len(args)))
+		}
+	} else {
+		fields = appendArgs(fields, args)
+	}
+	if result != nil {
+		if n, rerr := result.RowsAffected(); rerr == nil {
+			fields = append(fields, zlog.F("rows", n))
+		}
+	}
+	if err != nil {
+		fields = append(fields, zlog.Err(err))
+	}
+
+	cfg.Logger.LogEntry(&zlog.Entry{Level: level, Tag: Tag, Func: op, Message: query, Fields: fields})
+}
+
+/* 每个参数为一个字段，键为arg1、arg2…或参数名，字符串参数因此经过Logger的脱敏规则 */
+func appendArgs(fields []zlog.Field, args []driver.NamedValue) []zlog.Field {
+	for _, arg := range args {
+		key := arg.Name
+		if key == "" {
+			key = "arg" + strconv.Itoa(arg.Ordinal)
+		}
+
+		value := arg.Value
+		if b, ok := value.([]byte); ok {
+			value = "<" + strconv.Itoa(len(b)) + " bytes>"
+		}
+		fields = append(fields, zlog.F(key, value))
+	}
+	return fields
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func plainValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("zsql: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+type wrappedConn struct {
+	conn driver.Conn
+	cfg  *Config
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if p, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{stmt: stmt, query: query, cfg: c.cfg}, nil
+}
+
+func (c *wrappedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if b, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	c.cfg.log("Begin", "BEGIN", nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{tx: tx, cfg: c.cfg}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		res driver.Result
+		err = driver.ErrSkip
+	)
+	if e, ok := c.conn.(driver.ExecerContext); ok {
+		res, err = e.ExecContext(ctx, query, args)
+	} else if e, ok := c.conn.(driver.Execer); ok {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			res, err = e.Exec(query, values)
+		}
+	}
+	c.cfg.log("Exec", query, args, start, res, err)
+	return res, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  = driver.ErrSkip
+	)
+	if q, ok := c.conn.(driver.QueryerContext); ok {
+		rows, err = q.QueryContext(ctx, query, args)
+	} else if q, ok := c.conn.(driver.Queryer); ok {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			rows, err = q.Query(query, values)
+		}
+	}
+	c.cfg.log("Query", query, args, start, nil, err)
+	return rows, err
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type wrappedTx struct {
+	tx  driver.Tx
+	cfg *Config
+}
+
+func (t *wrappedTx) Commit() error {
+	start := time.Now()
+	err := t.tx.Commit()
+	t.cfg.log("Commit", "COMMIT", nil, start, nil, err)
+	return err
+}
+
+func (t *wrappedTx) Rollback() error {
+	start := time.Now()
+	err := t.tx.Rollback()
+	t.cfg.log("Rollback", "ROLLBACK", nil, start, nil, err)
+	return err
+}
+
+type wrappedStmt struct {
+	stmt  driver.Stmt
+	query string
+	cfg   *Config
+}
+
+func (s *wrappedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *wrappedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		res driver.Result
+		err error
+	)
+	if e, ok := s.stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			res, err = s.stmt.Exec(values)
+		}
+	}
+	s.cfg.log("Exec", s.query, args, start, res, err)
+	return res, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if q, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = plainValues(args); err == nil {
+			rows, err = s.stmt.Query(values)
+		}
+	}
+	s.cfg.log("Query", s.query, args, start, nil, err)
+	return rows, err
+}
+
+func (s *wrappedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,150 @@
+/*
+MIT License
+
+Copyright (c) 2019 Atlas Lee, 4859345@qq.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package zsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atlaslee/zlog"
+)
+
+/* 只支持预编译语句的驱动 */
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeStmt struct {
+	query string
+}
+
+type fakeTx struct{}
+
+type noRows struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(s.query, "SLOW") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if strings.HasPrefix(s.query, "BAD") {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) { return noRows{}, nil }
+
+func (noRows) Columns() []string              { return []string{"id"} }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+/* 不经sql.Register直接打开包装后的驱动，避免-count多次运行时重复注册而panic */
+type driverConnector struct {
+	d driver.Driver
+}
+
+func (c driverConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c driverConnector) Driver() driver.Driver                        { return c.d }
+
+func TestWrap(t *testing.T) {
+	var buf bytes.Buffer
+	l := zlog.NewLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&zlog.TextFormatter{NoColor: true})
+	l.RedactKeys("password", "arg3")
+
+	db := sql.OpenDB(driverConnector{Wrap(fakeDriver{}, Config{Logger: l, Slow: time.Millisecond})})
+	defer db.Close()
+
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = ? AND token = ?", "alice", 7, "t0k3n"); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("SLOW UPDATE")
+	db.Exec("BAD", "password=hunter2")
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	tx, _ := db.Begin()
+	tx.Commit()
+
+	out := buf.String()
+	for _, want := range []string{
+		"[DEBUG][sql: Exec] UPDATE users SET name = ? WHERE id = ? AND token = ? latency=",
+		" arg1=alice arg2=7 arg3=*** rows=3",
+		"[WARNING][sql: Exec] SLOW UPDATE latency=",
+		`[WARNING][sql: Exec] BAD latency=`,
+		`arg1="password=***" error="syntax error"`,
+		"[DEBUG][sql: Query] SELECT id FROM users latency=",
+		"[DEBUG][sql: Begin] BEGIN",
+		"[DEBUG][sql: Commit] COMMIT",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	l.SetTagLevel(zlog.WARNING, Tag)
+	db.Exec("UPDATE users SET name = ?", "bob")
+	if buf.Len() != 0 {
+		t.Errorf("debug logged: %q", buf.String())
+	}
+}
+
+func TestHideArgs(t *testing.T) {
+	var buf bytes.Buffer
+	l := zlog.NewLogger()
+	l.SetOutput(&buf)
+
+	db := sql.OpenDB(driverConnector{Wrap(fakeDriver{}, Config{Logger: l, HideArgs: true})})
+	defer db.Close()
+
+	db.Exec("INSERT INTO secrets VALUES (?, ?)", "a", "b")
+	if !strings.Contains(buf.String(), " args=2 rows=2") || strings.Contains(buf.String(), "arg1") {
+		t.Errorf("hidden args: %q", buf.String())
+	}
+}